@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/flaviostutz/signalutils"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//apiPoint a timeseries value
+type apiPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+//apiSeries current value and recent values of a metric. Current is -1 when there is not enough data
+type apiSeries struct {
+	Current float64    `json:"current"`
+	Values  []apiPoint `json:"values"`
+}
+
+type apiCPU struct {
+	Name   string    `json:"name"`
+	Idle   apiSeries `json:"idle"`
+	User   apiSeries `json:"user"`
+	System apiSeries `json:"system"`
+	IOWait apiSeries `json:"iowait"`
+	Steal  apiSeries `json:"steal"`
+}
+
+type apiDisk struct {
+	Name           string    `json:"name"`
+	ReadBps        apiSeries `json:"readBps"`
+	WriteBps       apiSeries `json:"writeBps"`
+	ReadOps        apiSeries `json:"readOps"`
+	WriteOps       apiSeries `json:"writeOps"`
+	IopsInProgress apiSeries `json:"iopsInProgress"`
+}
+
+type apiPartition struct {
+	Path        string    `json:"path"`
+	Fstype      string    `json:"fstype"`
+	Total       uint64    `json:"total"`
+	Free        apiSeries `json:"free"`
+	InodesTotal uint64    `json:"inodesTotal"`
+	InodesFree  apiSeries `json:"inodesFree"`
+}
+
+type apiNIC struct {
+	Name       string    `json:"name"`
+	RecvBps    apiSeries `json:"recvBps"`
+	SentBps    apiSeries `json:"sentBps"`
+	RecvPps    apiSeries `json:"recvPps"`
+	SentPps    apiSeries `json:"sentPps"`
+	ErrInRate  apiSeries `json:"errInRate"`
+	ErrOutRate apiSeries `json:"errOutRate"`
+}
+
+type apiProcess struct {
+	Pid         int32     `json:"pid"`
+	Name        string    `json:"name"`
+	CPU         apiSeries `json:"cpu"`
+	MemoryTotal apiSeries `json:"memoryTotal"`
+	MemorySwap  apiSeries `json:"memorySwap"`
+	ReadBps     apiSeries `json:"readBps"`
+	WriteBps    apiSeries `json:"writeBps"`
+	RecvBps     apiSeries `json:"recvBps"`
+	SentBps     apiSeries `json:"sentBps"`
+	FD          apiSeries `json:"fd"`
+	Connections apiSeries `json:"connections"`
+}
+
+//registerAPI adds the JSON API routes to router
+func registerAPI(ctx context.Context, router *mux.Router, ps *perfstat.Perfstat) {
+	hn := hostname()
+
+	router.HandleFunc("/issues", func(w http.ResponseWriter, r *http.Request) {
+		minScore := 0.0
+		ms := r.URL.Query().Get("minScore")
+		if ms != "" {
+			var err error
+			minScore, err = strconv.ParseFloat(ms, 64)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, "minScore must be a number")
+				return
+			}
+		}
+		id := r.URL.Query().Get("id")
+		_, err := regexp.Compile(id)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid id regex. err=%s", err))
+			return
+		}
+		dr := ps.TopCriticity(minScore, r.URL.Query().Get("type"), id, false)
+		writeAPIJSON(w, perfstat.NewJSONResults(perfstat.JSONKindSnapshot, hn, dr))
+	}).Methods("GET")
+
+	router.HandleFunc("/score", func(w http.ResponseWriter, r *http.Request) {
+		typ := r.URL.Query().Get("type")
+		group := r.URL.Query().Get("group")
+		writeAPIJSON(w, map[string]interface{}{
+			"type":  typ,
+			"group": group,
+			"score": ps.Score(typ, groupRegex(group)),
+		})
+	}).Methods("GET")
+
+	router.HandleFunc("/issues/stream", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeAPIError(w, http.StatusInternalServerError, "streaming not supported")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		flusher.Flush()
+
+		events := make(chan perfstat.IssueEvent, 100)
+		unwatch := ps.Watch(events)
+		defer unwatch()
+		keepAlive := time.NewTicker(15 * time.Second)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ctx.Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprintf(w, ": keep-alive\n\n")
+				flusher.Flush()
+			case e := <-events:
+				b, err := json.Marshal(perfstat.NewJSONEvent(hn, e))
+				if err != nil {
+					logrus.Warnf("Error serializing issue event. err=%s", err)
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Typ, b)
+				flusher.Flush()
+			}
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/stats/{group}", func(w http.ResponseWriter, r *http.Request) {
+		window := 1 * time.Minute
+		ws := r.URL.Query().Get("window")
+		if ws != "" {
+			var err error
+			window, err = time.ParseDuration(ws)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, "window must be a duration. ex.: 5m")
+				return
+			}
+		}
+		if detectors.ActiveStats == nil {
+			writeAPIError(w, http.StatusServiceUnavailable, "stats not started yet")
+			return
+		}
+
+		opt := detectors.Opt
+		as := detectors.ActiveStats
+		switch mux.Vars(r)["group"] {
+		case "cpu":
+			cpus := make([]apiCPU, 0)
+			for i, c := range as.CPUStats.CPUsSnapshot() {
+				cpus = append(cpus, cpuTimesSeries(fmt.Sprintf("cpu%d", i), c, opt.CPULoadAvgDuration, window))
+			}
+			writeAPIJSON(w, map[string]interface{}{
+				"total": cpuTimesSeries("total", as.CPUStats.Total, opt.CPULoadAvgDuration, window),
+				"cpus":  cpus,
+			})
+
+		case "mem":
+			ms := as.MemStats
+			writeAPIJSON(w, map[string]interface{}{
+				"total":      ms.Total,
+				"available":  gaugeSeries(&ms.Available, window),
+				"used":       gaugeSeries(&ms.Used, window),
+				"free":       gaugeSeries(&ms.Free, window),
+				"swapTotal":  ms.SwapTotal,
+				"swapUsed":   gaugeSeries(&ms.SwapUsed, window),
+				"swapFree":   gaugeSeries(&ms.SwapFree, window),
+				"swapInBps":  rateSeries(&ms.SwapIn, opt.IORateLoadDuration, window),
+				"swapOutBps": rateSeries(&ms.SwapOut, opt.IORateLoadDuration, window),
+			})
+
+		case "disk":
+			ds := as.DiskStats
+			disks := make([]apiDisk, 0)
+			for _, d := range ds.DisksSnapshot() {
+				disks = append(disks, apiDisk{
+					Name:           d.Name,
+					ReadBps:        rateSeries(&d.ReadBytes, opt.IORateLoadDuration, window),
+					WriteBps:       rateSeries(&d.WriteBytes, opt.IORateLoadDuration, window),
+					ReadOps:        rateSeries(&d.ReadCount, opt.IORateLoadDuration, window),
+					WriteOps:       rateSeries(&d.WriteCount, opt.IORateLoadDuration, window),
+					IopsInProgress: gaugeSeries(&d.IopsInProgress, window),
+				})
+			}
+			sort.Slice(disks, func(i, j int) bool { return disks[i].Name < disks[j].Name })
+			partitions := make([]apiPartition, 0)
+			for _, p := range ds.PartitionsSnapshot() {
+				partitions = append(partitions, apiPartition{
+					Path:        p.Path,
+					Fstype:      p.Fstype,
+					Total:       p.Total,
+					Free:        gaugeSeries(&p.Free, window),
+					InodesTotal: p.InodesTotal,
+					InodesFree:  gaugeSeries(&p.InodesFree, window),
+				})
+			}
+			sort.Slice(partitions, func(i, j int) bool { return partitions[i].Path < partitions[j].Path })
+			writeAPIJSON(w, map[string]interface{}{
+				"disks":      disks,
+				"partitions": partitions,
+				"fd": map[string]interface{}{
+					"used": gaugeSeries(&ds.FD.UsedFD, window),
+					"max":  ds.FD.MaxFD,
+				},
+			})
+
+		case "net":
+			nics := make([]apiNIC, 0)
+			for _, n := range as.NetStats.NICsSnapshot() {
+				nics = append(nics, apiNIC{
+					Name:       n.Name,
+					RecvBps:    rateSeries(&n.BytesRecv, opt.IORateLoadDuration, window),
+					SentBps:    rateSeries(&n.BytesSent, opt.IORateLoadDuration, window),
+					RecvPps:    rateSeries(&n.PacketsRecv, opt.IORateLoadDuration, window),
+					SentPps:    rateSeries(&n.PacketsSent, opt.IORateLoadDuration, window),
+					ErrInRate:  rateSeries(&n.ErrIn, opt.IORateLoadDuration, window),
+					ErrOutRate: rateSeries(&n.ErrOut, opt.IORateLoadDuration, window),
+				})
+			}
+			sort.Slice(nics, func(i, j int) bool { return nics[i].Name < nics[j].Name })
+			writeAPIJSON(w, map[string]interface{}{
+				"nics": nics,
+			})
+
+		case "process":
+			top := 10
+			ts := r.URL.Query().Get("top")
+			if ts != "" {
+				var err error
+				top, err = strconv.Atoi(ts)
+				if err != nil || top < 1 {
+					writeAPIError(w, http.StatusBadRequest, "top must be a positive number")
+					return
+				}
+			}
+			var procs []*stats.ProcessMetrics
+			switch r.URL.Query().Get("sort") {
+			case "", "cpu":
+				procs = as.ProcessStats.TopCPULoad()
+			case "mem":
+				procs = as.ProcessStats.TopMemUsed()
+			case "swap":
+				procs = as.ProcessStats.TopMemSwap()
+			case "read":
+				procs = as.ProcessStats.TopIOByteRate(true)
+			case "write":
+				procs = as.ProcessStats.TopIOByteRate(false)
+			case "recv":
+				procs = as.ProcessStats.TopNetByteRate(true)
+			case "sent":
+				procs = as.ProcessStats.TopNetByteRate(false)
+			case "fd":
+				procs = as.ProcessStats.TopFD()
+			default:
+				writeAPIError(w, http.StatusBadRequest, "sort must be one of cpu, mem, swap, read, write, recv, sent, fd")
+				return
+			}
+			processes := make([]apiProcess, 0)
+			for _, p := range procs {
+				if len(processes) >= top {
+					break
+				}
+				cpu := loadSeries(&p.CPUTimes.User, opt.CPULoadAvgDuration, window)
+				sys := loadSeries(&p.CPUTimes.System, opt.CPULoadAvgDuration, window)
+				if cpu.Current != -1 && sys.Current != -1 {
+					cpu.Current = cpu.Current + sys.Current
+				}
+				cpu.Values = sumPoints(cpu.Values, sys.Values)
+				processes = append(processes, apiProcess{
+					Pid:         p.Pid,
+					Name:        p.Name,
+					CPU:         cpu,
+					MemoryTotal: gaugeSeries(&p.MemoryTotal, window),
+					MemorySwap:  gaugeSeries(&p.MemorySwap, window),
+					ReadBps:     rateSeries(&p.IOCounters.ReadBytes, opt.IORateLoadDuration, window),
+					WriteBps:    rateSeries(&p.IOCounters.WriteBytes, opt.IORateLoadDuration, window),
+					RecvBps:     rateSeries(&p.TotalNetIOCounters.BytesRecv, opt.IORateLoadDuration, window),
+					SentBps:     rateSeries(&p.TotalNetIOCounters.BytesSent, opt.IORateLoadDuration, window),
+					FD:          gaugeSeries(&p.FD, window),
+					Connections: gaugeSeries(&p.Connections, window),
+				})
+			}
+			writeAPIJSON(w, map[string]interface{}{
+				"processes": processes,
+			})
+
+		default:
+			writeAPIError(w, http.StatusNotFound, "stats group must be one of cpu, mem, disk, net, process")
+		}
+	}).Methods("GET")
+}
+
+func cpuTimesSeries(name string, c *stats.CPUTimes, loadTime time.Duration, window time.Duration) apiCPU {
+	return apiCPU{
+		Name:   name,
+		Idle:   loadSeries(&c.Idle, loadTime, window),
+		User:   loadSeries(&c.User, loadTime, window),
+		System: loadSeries(&c.System, loadTime, window),
+		IOWait: loadSeries(&c.IOWait, loadTime, window),
+		Steal:  loadSeries(&c.Steal, loadTime, window),
+	}
+}
+
+//gaugeSeries values as they were sampled
+func gaugeSeries(ts *signalutils.Timeseries, window time.Duration) apiSeries {
+	s := apiSeries{Current: -1}
+	l, ok := ts.Last()
+	if ok {
+		s.Current = apiFloat(l.Value)
+	}
+	tvs := stats.TimeValuesRange(ts, time.Now().Add(-window), time.Now())
+	s.Values = apiPoints(tvs)
+	return s
+}
+
+//rateSeries rate per second of a counter calculated over rateLen
+func rateSeries(tcr *signalutils.TimeseriesCounterRate, rateLen time.Duration, window time.Duration) apiSeries {
+	s := apiSeries{Current: -1, Values: make([]apiPoint, 0)}
+	r, ok := tcr.Rate(rateLen)
+	if ok {
+		s.Current = apiFloat(r)
+	}
+	rts, ok := tcr.RateOverTime(rateLen, window)
+	if ok {
+		s.Values = apiPoints(rts.Values)
+	}
+	return s
+}
+
+//loadSeries percentage of time spent between samples of a cumulative time counter (ex.: cpu user time)
+func loadSeries(ts *signalutils.Timeseries, loadTime time.Duration, window time.Duration) apiSeries {
+	s := apiSeries{Current: -1}
+	l, ok := stats.TimeLoadPerc(ts, loadTime)
+	if ok {
+		s.Current = apiFloat(l)
+	}
+	tvs := stats.TimeValuesRange(ts, time.Now().Add(-window), time.Now())
+	loads := make([]signalutils.TimeValue, 0)
+	for i := 1; i < len(tvs); i++ {
+		dt := tvs[i].Time.Sub(tvs[i-1].Time).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		loads = append(loads, signalutils.TimeValue{Time: tvs[i].Time, Value: (tvs[i].Value - tvs[i-1].Value) / dt})
+	}
+	s.Values = apiPoints(loads)
+	return s
+}
+
+func sumPoints(p1 []apiPoint, p2 []apiPoint) []apiPoint {
+	if len(p1) != len(p2) {
+		return p1
+	}
+	sum := make([]apiPoint, len(p1))
+	for i := range p1 {
+		sum[i] = apiPoint{Time: p1[i].Time, Value: p1[i].Value + p2[i].Value}
+	}
+	return sum
+}
+
+func apiPoints(tvs []signalutils.TimeValue) []apiPoint {
+	ps := make([]apiPoint, 0)
+	for _, tv := range tvs {
+		if math.IsNaN(tv.Value) || math.IsInf(tv.Value, 0) {
+			continue
+		}
+		ps = append(ps, apiPoint{Time: tv.Time, Value: tv.Value})
+	}
+	return ps
+}
+
+func apiFloat(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return -1
+	}
+	return v
+}
+
+func writeAPIJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		logrus.Warnf("Error writing API response. err=%s", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
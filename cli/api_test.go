@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/gorilla/mux"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI(t *testing.T) {
+	//memory usage goes from 20% to 99% after 3s, sampled at 10Hz
+	tl := stats.Timeline{}
+	for i := 0; i < 600; i++ {
+		v := float64(i) * 0.1
+		tl.CPU = append(tl.CPU, stats.CPUSample{
+			Total: cpu.TimesStat{User: v / 2, Idle: v / 2},
+			CPUs:  []cpu.TimesStat{{CPU: "cpu0", User: v / 2, Idle: v / 2}},
+		})
+		used := uint64(200000000)
+		if i >= 30 {
+			used = 990000000
+		}
+		tl.Mem = append(tl.Mem, stats.MemSample{
+			Memory: mem.VirtualMemoryStat{Total: 1000000000, Used: used, Available: 1000000000 - used},
+		})
+		tl.FD = append(tl.FD, stats.FDSample{Used: 100, Max: 100000})
+		tl.DiskIO = append(tl.DiskIO, map[string]disk.IOCountersStat{
+			"sda": {Name: "sda", ReadBytes: uint64(i) * 1000},
+		})
+		tl.Partitions = append(tl.Partitions, []disk.UsageStat{
+			{Path: "/", Fstype: "ext4", Total: 1000000, Free: 900000, InodesTotal: 1000, InodesFree: 900},
+		})
+		tl.NICs = append(tl.NICs, []net.IOCountersStat{
+			{Name: "eth0", BytesSent: uint64(i) * 100, BytesRecv: uint64(i) * 100},
+		})
+		tl.Processes = append(tl.Processes, []stats.ProcessSample{
+			{Pid: 123, Name: "java", Times: &cpu.TimesStat{User: v / 2}, MemoryInfo: &process.MemoryInfoStat{RSS: used}},
+		})
+	}
+
+	opt := detectors.NewOptions()
+	opt.DefaultSampleFreq = 10
+	opt.MemAvgDuration = 1 * time.Second
+	opt.CPULoadAvgDuration = 1 * time.Second
+	opt.IORateLoadDuration = 1 * time.Second
+	opt.Source = stats.NewScriptedSource(tl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	detectors.Started = false
+	ps := perfstat.Start(ctx, opt)
+
+	router := mux.NewRouter()
+	registerAPI(ctx, router, ps)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("issues stream", func(t *testing.T) {
+		rctx, rcancel := context.WithTimeout(ctx, 10*time.Second)
+		defer rcancel()
+		req, _ := http.NewRequestWithContext(rctx, "GET", server.URL+"/issues/stream", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		sc := bufio.NewScanner(resp.Body)
+		typ := ""
+		for sc.Scan() {
+			line := sc.Text()
+			if strings.HasPrefix(line, "event: ") {
+				typ = strings.TrimPrefix(line, "event: ")
+			}
+			if strings.HasPrefix(line, "data: ") {
+				e := perfstat.JSONEvent{}
+				assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+				if e.Issue.ID == "mem-low" {
+					assert.Equal(t, perfstat.IssueOpened, typ)
+					assert.Equal(t, perfstat.IssueOpened, e.Event)
+					return
+				}
+			}
+		}
+		t.Errorf("mem-low issue not opened. err=%v", sc.Err())
+	})
+
+	t.Run("issues", func(t *testing.T) {
+		res := perfstat.JSONResults{}
+		status := getAPI(t, server.URL+"/issues?minScore=0.5&id=^mem-", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, perfstat.JSONKindSnapshot, res.Kind)
+		if assert.NotEmpty(t, res.Results) {
+			for _, r := range res.Results {
+				assert.True(t, strings.HasPrefix(r.ID, "mem-"))
+				assert.GreaterOrEqual(t, r.Score, 0.5)
+			}
+		}
+
+		status = getAPI(t, server.URL+"/issues?minScore=high", &map[string]string{})
+		assert.Equal(t, http.StatusBadRequest, status)
+		status = getAPI(t, server.URL+"/issues?id=(", &map[string]string{})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("score", func(t *testing.T) {
+		res := map[string]interface{}{}
+		status := getAPI(t, server.URL+"/score?group=mem", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "mem", res["group"])
+		assert.Equal(t, 1.0, res["score"])
+
+		status = getAPI(t, server.URL+"/score?group=net", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 0.0, res["score"])
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		res := map[string]interface{}{}
+		status := getAPI(t, server.URL+"/stats/cpu", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, len(res["cpus"].([]interface{})))
+
+		status = getAPI(t, server.URL+"/stats/mem?window=10s", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1000000000.0, res["total"])
+
+		res = map[string]interface{}{}
+		status = getAPI(t, server.URL+"/stats/disk", &res)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, len(res["disks"].([]interface{})))
+		assert.Equal(t, 1, len(res["partitions"].([]interface{})))
+
+		res = map[string]interface{}{}
+		status = getAPI(t, server.URL+"/stats/net", &res)
+		assert.Equal(t, http.StatusOK, status)
+		nics := res["nics"].([]interface{})
+		if assert.Equal(t, 1, len(nics)) {
+			assert.Equal(t, "eth0", nics[0].(map[string]interface{})["name"])
+		}
+
+		res = map[string]interface{}{}
+		status = getAPI(t, server.URL+"/stats/process?sort=mem&top=1", &res)
+		assert.Equal(t, http.StatusOK, status)
+		procs := res["processes"].([]interface{})
+		if assert.Equal(t, 1, len(procs)) {
+			assert.Equal(t, "java", procs[0].(map[string]interface{})["name"])
+		}
+
+		status = getAPI(t, server.URL+"/stats/process?sort=size", &map[string]string{})
+		assert.Equal(t, http.StatusBadRequest, status)
+		status = getAPI(t, server.URL+"/stats/mem?window=long", &map[string]string{})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		errRes := map[string]string{}
+		status = getAPI(t, server.URL+"/stats/gpu", &errRes)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Contains(t, errRes["error"], "must be one of")
+	})
+}
+
+//getAPI decodes the JSON response of url into v and returns the response status
+func getAPI(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if !assert.Nil(t, err) {
+		return 0
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
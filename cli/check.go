@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/detectors"
+)
+
+//Nagios plugin exit codes
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+var checkStatusNames = map[int]string{
+	checkOK:       "OK",
+	checkWarning:  "WARNING",
+	checkCritical: "CRITICAL",
+	checkUnknown:  "UNKNOWN",
+}
+
+//exitInvalidOptions prints an invalid config or option error and exits
+//check mode reports it as UNKNOWN so that it is never taken as CRITICAL
+func exitInvalidOptions(mode string, msg string) {
+	if mode == "check" {
+		fmt.Printf("PERFSTAT UNKNOWN - %s\n", msg)
+		os.Exit(checkUnknown)
+	}
+	fmt.Println(msg)
+	os.Exit(1)
+}
+
+//runCheck waits for enough samples, runs detections once and prints a Nagios plugin output
+//returns the Nagios exit code
+func runCheck(opt Option, ps *perfstat.Perfstat, started time.Time) int {
+	wait := opt.checkDuration - time.Since(started)
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	results, err := ps.DetectNow()
+	if err != nil {
+		fmt.Printf("PERFSTAT UNKNOWN - %s\n", err)
+		return checkUnknown
+	}
+
+	code, output := checkOutput(results, opt.checkWarn, opt.checkCrit, opt.checkGroup)
+	fmt.Println(output)
+	return code
+}
+
+//checkOutput returns the exit code and the output line with perfdata for detection results
+func checkOutput(results []detectors.DetectionResult, warn float64, crit float64, group string) (int, string) {
+	drs := make([]detectors.DetectionResult, 0)
+	for _, dr := range results {
+		//-1 means not enough data yet
+		if dr.Score < 0 {
+			continue
+		}
+		if group != "" && detectors.GroupFromID(dr.ID) != group {
+			continue
+		}
+		drs = append(drs, dr)
+	}
+	sort.SliceStable(drs, func(i, j int) bool {
+		return drs[i].Score > drs[j].Score
+	})
+
+	if len(drs) == 0 {
+		return checkUnknown, "PERFSTAT UNKNOWN - no detection results. Try a longer --duration"
+	}
+
+	code := checkOK
+	issues := make([]string, 0)
+	for _, dr := range drs {
+		if dr.Score >= crit {
+			code = checkCritical
+		} else if dr.Score >= warn {
+			if code == checkOK {
+				code = checkWarning
+			}
+		} else {
+			continue
+		}
+		_, valueStr, unit := formatResPropertyValue(dr.Res)
+		issues = append(issues, fmt.Sprintf("%s %s=%s%s score=%.2f", dr.ID, dr.Res.Name, valueStr, unit, dr.Score))
+	}
+
+	summary := fmt.Sprintf("no issues above %.2f", warn)
+	if len(issues) > 0 {
+		summary = issues[0]
+		if len(issues) > 1 {
+			summary = fmt.Sprintf("%s (and %d more)", summary, len(issues)-1)
+		}
+	}
+
+	perfdata := make([]string, 0)
+	for _, dr := range drs {
+		label := strings.NewReplacer("'", "_", "=", "_").Replace(fmt.Sprintf("%s %s", dr.ID, dr.Res.Name))
+		perfdata = append(perfdata, fmt.Sprintf("'%s'=%.2f;%.2f;%.2f;0;1", label, dr.Score, warn, crit))
+	}
+
+	return code, fmt.Sprintf("PERFSTAT %s - %s | %s", checkStatusNames[code], summary, strings.Join(perfdata, " "))
+}
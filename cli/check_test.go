@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOutput(t *testing.T) {
+	memLow := detectors.DetectionResult{Typ: "bottleneck", ID: "mem-low", Score: 0.9, Res: detectors.Resource{Typ: "mem", Name: "ram", PropertyName: "used-perc", PropertyValue: 0.95}}
+	diskLow := detectors.DetectionResult{Typ: "risk", ID: "disk-low-space", Score: 0.6, Res: detectors.Resource{Typ: "disk", Name: "/", PropertyName: "used-perc", PropertyValue: 0.85}}
+	cpuIdle := detectors.DetectionResult{Typ: "bottleneck", ID: "cpu-low-idle", Score: 0.1, Res: detectors.Resource{Typ: "cpu", Name: "cpu", PropertyName: "idle-perc", PropertyValue: 0.7}}
+	noData := detectors.DetectionResult{Typ: "bottleneck", ID: "net-errors", Score: -1, Res: detectors.Resource{Typ: "nic", Name: "eth0", PropertyName: "errors-ops", PropertyValue: 0}}
+
+	cases := []struct {
+		name    string
+		results []detectors.DetectionResult
+		group   string
+		code    int
+		output  string
+	}{
+		{
+			name:    "ok",
+			results: []detectors.DetectionResult{cpuIdle, noData},
+			code:    checkOK,
+			output:  "PERFSTAT OK - no issues above 0.50 | 'cpu-low-idle cpu'=0.10;0.50;0.80;0;1",
+		},
+		{
+			name:    "warning",
+			results: []detectors.DetectionResult{cpuIdle, diskLow},
+			code:    checkWarning,
+			output:  "PERFSTAT WARNING - disk-low-space /=85% score=0.60 | 'disk-low-space /'=0.60;0.50;0.80;0;1 'cpu-low-idle cpu'=0.10;0.50;0.80;0;1",
+		},
+		{
+			name:    "critical",
+			results: []detectors.DetectionResult{diskLow, cpuIdle, memLow},
+			code:    checkCritical,
+			output:  "PERFSTAT CRITICAL - mem-low ram=95% score=0.90 (and 1 more) | 'mem-low ram'=0.90;0.50;0.80;0;1 'disk-low-space /'=0.60;0.50;0.80;0;1 'cpu-low-idle cpu'=0.10;0.50;0.80;0;1",
+		},
+		{
+			name:    "group",
+			results: []detectors.DetectionResult{diskLow, cpuIdle, memLow},
+			group:   "disk",
+			code:    checkWarning,
+			output:  "PERFSTAT WARNING - disk-low-space /=85% score=0.60 | 'disk-low-space /'=0.60;0.50;0.80;0;1",
+		},
+		{
+			name:    "unknown",
+			results: []detectors.DetectionResult{noData},
+			code:    checkUnknown,
+			output:  "PERFSTAT UNKNOWN - no detection results. Try a longer --duration",
+		},
+		{
+			name:    "perfdata labels",
+			results: []detectors.DetectionResult{{Typ: "risk", ID: "disk-low-space", Score: 0.2, Res: detectors.Resource{Typ: "disk", Name: "/mnt/a'b=c", PropertyName: "used-perc", PropertyValue: 0.4}}},
+			code:    checkOK,
+			output:  "PERFSTAT OK - no issues above 0.50 | 'disk-low-space /mnt/a_b_c'=0.20;0.50;0.80;0;1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, output := checkOutput(c.results, 0.5, 0.8, c.group)
+			assert.Equal(t, c.code, code)
+			assert.Equal(t, c.output, output)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -37,6 +38,9 @@ type detail struct {
 	sparkline3   *sparkline.SparkLine
 	sparkSeries3 *signalutils.Timeseries
 
+	sparkline4   *sparkline.SparkLine
+	sparkSeries4 *signalutils.Timeseries
+
 	bottleneckText *text.Text
 	riskText       *text.Text
 
@@ -99,6 +103,13 @@ func newDetails(group string, opt Option, ps *perfstat.Perfstat) (*detail, error
 	ts3 := signalutils.NewTimeseries(4 * time.Minute)
 	h.sparkSeries3 = &ts3
 
+	h.sparkline4, err = sparkline.New(sparkline.Color(cell.ColorYellow))
+	if err != nil {
+		return nil, err
+	}
+	ts4 := signalutils.NewTimeseries(4 * time.Minute)
+	h.sparkSeries4 = &ts4
+
 	//DETAIL TEXTS
 	h.bottleneckText, err = text.New()
 	if err != nil {
@@ -170,12 +181,24 @@ func newDetails(group string, opt Option, ps *perfstat.Perfstat) (*detail, error
 									container.PaddingRight(3),
 								),
 								container.Right(
-									container.PlaceWidget(h.sparkline2),
-									container.PaddingTop(0),
-									container.PaddingBottom(2),
-									container.PaddingLeft(3),
-									container.PaddingRight(3),
+									container.SplitVertical(
+										container.Left(
+											container.PlaceWidget(h.sparkline2),
+											container.PaddingTop(0),
+											container.PaddingBottom(2),
+											container.PaddingLeft(3),
+											container.PaddingRight(3),
+										),
+										container.Right(
+											container.PlaceWidget(h.sparkline4),
+											container.PaddingTop(0),
+											container.PaddingBottom(2),
+											container.PaddingLeft(3),
+											container.PaddingRight(3),
+										),
+									),
 								),
+								container.SplitPercent(33),
 							),
 						),
 					),
@@ -240,7 +263,7 @@ func (h *detail) update(opt Option, ps *perfstat.Perfstat, paused bool, term *te
 	}
 
 	//HEADER
-	scc := ps.Score("", fmt.Sprintf("%s.*", h.group))
+	scc := ps.Score("", groupRegex(h.group))
 
 	color := cell.ColorRed
 	bvalue := perc(scc)
@@ -265,6 +288,7 @@ func (h *detail) update(opt Option, ps *perfstat.Perfstat, paused bool, term *te
 		updateSparkSeriesTimeLoad(&detectors.ActiveStats.CPUStats.Total.User, h.sparkSeries1, "User", h.sparkline1, false)
 		updateSparkSeriesTimeLoad(&detectors.ActiveStats.CPUStats.Total.IOWait, h.sparkSeries2, "IOWait", h.sparkline2, false)
 		updateSparkSeriesTimeLoad(&detectors.ActiveStats.CPUStats.Total.Idle, h.sparkSeries3, "Total", h.sparkline3, true)
+		updateSparkSeriesTimeLoad(&detectors.ActiveStats.CPUStats.Total.Steal, h.sparkSeries4, "Steal", h.sparkline4, false)
 
 	} else if h.group == "mem" {
 		used, ok := detectors.ActiveStats.MemStats.Used.Last()
@@ -339,6 +363,12 @@ func (h *detail) update(opt Option, ps *perfstat.Perfstat, paused bool, term *te
 		}
 		updateSparkSeriesAbsoluteMax(errorsTotal, "ops", h.sparkSeries3, "Errors", h.sparkline3, -1)
 
+		ss := detectors.ActiveStats.SocketStats
+		used, ok := ss.EphemeralPortsUsed.Last()
+		if ok && ss.EphemeralPortsTotal > 0 {
+			updateSparkSeriesAbsoluteMax(used.Value, "", h.sparkSeries4, "Eph Ports", h.sparkline4, float64(ss.EphemeralPortsTotal))
+		}
+
 		rwc := 0.0
 		rwb := 0.0
 		for _, nic := range detectors.ActiveStats.NetStats.NICs {
@@ -366,14 +396,46 @@ func (h *detail) update(opt Option, ps *perfstat.Perfstat, paused bool, term *te
 		updateSparkSeriesAbsoluteMax(rwc, "pps", h.sparkSeries1, "IN/OUT", h.sparkline1, -1)
 		updateSparkSeriesAbsoluteMax(rwb, "bps", h.sparkSeries2, "IN/OUT", h.sparkline2, -1)
 
+	} else if h.group == "container" {
+		worstMax := 0.0
+		worstUsed := 0.0
+		cpuTotal := 0.0
+		worstThrottled := 0.0
+		for _, cm := range detectors.ActiveStats.CgroupStats.Cgroups {
+			ws, ok := cm.MemoryWorkingSet.Last()
+			if ok && cm.MemoryMax > 0 && ws.Value/float64(cm.MemoryMax) > worstUsed/math.Max(worstMax, 1) {
+				worstUsed = ws.Value
+				worstMax = float64(cm.MemoryMax)
+			}
+
+			cu, ok := cm.CPUUsage.Rate(4 * time.Second)
+			if ok {
+				cpuTotal = cpuTotal + cu
+			}
+
+			p, ok := cm.NrPeriods.Rate(4 * time.Second)
+			if !ok || p == 0 {
+				continue
+			}
+			th, ok := cm.NrThrottled.Rate(4 * time.Second)
+			if ok && th/p > worstThrottled {
+				worstThrottled = th / p
+			}
+		}
+		if worstMax > 0 {
+			updateSparkSeriesAbsoluteMax(worstUsed, "B", h.sparkSeries3, "Mem Limit", h.sparkline3, worstMax)
+		}
+		updateSparkSeriesAbsoluteMax(cpuTotal, "cpus", h.sparkSeries1, "CPU", h.sparkline1, -1)
+		updateSparkSeriesAbsoluteMax(worstThrottled, "perc", h.sparkSeries2, "Throttled", h.sparkline2, -1)
+
 	}
 
 	//BOTTLENECK DETAILS
-	dr := ps.TopCriticity(0.01, "bottleneck", fmt.Sprintf("%s.*", h.group), false)
+	dr := append(ps.TopCriticity(0.01, "harm", groupRegex(h.group), false), ps.TopCriticity(0.01, "bottleneck", groupRegex(h.group), false)...)
 	h.bottleneckText.Write(detectionTxt(dr), text.WriteReplace())
 
 	//RISK DETAILS
-	dr = ps.TopCriticity(0.01, "risk", fmt.Sprintf("%s.*", h.group), false)
+	dr = ps.TopCriticity(0.01, "risk", groupRegex(h.group), false)
 	h.riskText.Write(detectionTxt(dr), text.WriteReplace())
 
 	return nil
@@ -410,7 +472,7 @@ func detectionTxt(dr []detectors.DetectionResult) string {
 			if !found {
 				related[k] = "OK"
 				pn, pv, unit := formatResPropertyValue(rel)
-				r = fmt.Sprintf("%s\n  > %s %s %s%s", r, rel.Name, pn, pv, unit)
+				r = fmt.Sprintf("%s\n  > %s %s %s%s", r, resourceName(rel), pn, pv, unit)
 			}
 		}
 	}
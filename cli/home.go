@@ -32,6 +32,8 @@ type home struct {
 	diskText   *text.Text
 	netButton  *button.Button
 	netText    *text.Text
+	ctnrButton *button.Button
+	ctnrText   *text.Text
 
 	memButtonr  *button.Button
 	memTextr    *text.Text
@@ -39,6 +41,8 @@ type home struct {
 	diskTextr   *text.Text
 	netButtonr  *button.Button
 	netTextr    *text.Text
+	ctnrButtonr *button.Button
+	ctnrTextr   *text.Text
 
 	relatedText  *text.Text
 	dangerSeries *signalutils.Timeseries
@@ -94,6 +98,11 @@ func newHome(opt Option, ps *perfstat.Perfstat) (*home, error) {
 		return nil, err
 	}
 
+	h.ctnrButton, h.ctnrText, err = subsystemBox(nil, nil, "CONTAINER", 0, "6", 15, 5, " ")
+	if err != nil {
+		return nil, err
+	}
+
 	h.diskButtonr, h.diskTextr, err = subsystemBox(nil, nil, "DISK", 0, "3", 15, 3, " ")
 	if err != nil {
 		return nil, err
@@ -109,6 +118,11 @@ func newHome(opt Option, ps *perfstat.Perfstat) (*home, error) {
 		return nil, err
 	}
 
+	h.ctnrButtonr, h.ctnrTextr, err = subsystemBox(nil, nil, "CONTAINER", 0, "6", 15, 3, " ")
+	if err != nil {
+		return nil, err
+	}
+
 	h.relatedText, err = text.New()
 	if err != nil {
 		return nil, err
@@ -179,33 +193,50 @@ func newHome(opt Option, ps *perfstat.Perfstat) (*home, error) {
 							),
 						),
 						container.Bottom(
-							container.SplitVertical(
-								container.Left(
+							container.SplitHorizontal(
+								container.Top(
 									container.SplitVertical(
 										container.Left(
-											container.PlaceWidget(h.diskButton),
-											container.ID("diskButton"),
+											container.SplitVertical(
+												container.Left(
+													container.PlaceWidget(h.diskButton),
+													container.ID("diskButton"),
+												),
+												container.Right(
+													container.PlaceWidget(h.diskText),
+												),
+												container.SplitFixed(20),
+											),
 										),
 										container.Right(
-											container.PlaceWidget(h.diskText),
+											container.SplitVertical(
+												container.Left(
+													container.PlaceWidget(h.netButton),
+													container.ID("netButton"),
+												),
+												container.Right(
+													container.PlaceWidget(h.netText),
+												),
+												container.SplitFixed(20),
+											),
 										),
-										container.SplitFixed(20),
 									),
 								),
-								container.Right(
+								container.Bottom(
 									container.SplitVertical(
 										container.Left(
-											container.PlaceWidget(h.netButton),
-											container.ID("netButton"),
+											container.PlaceWidget(h.ctnrButton),
+											container.ID("ctnrButton"),
 										),
 										container.Right(
-											container.PlaceWidget(h.netText),
+											container.PlaceWidget(h.ctnrText),
 										),
 										container.SplitFixed(20),
 									),
 								),
 							),
 						),
+						container.SplitPercent(34),
 					),
 				),
 
@@ -247,18 +278,33 @@ func newHome(opt Option, ps *perfstat.Perfstat) (*home, error) {
 									),
 								),
 								container.Bottom(
-									container.SplitVertical(
-										container.Left(
-											container.PlaceWidget(h.netButtonr),
-											container.ID("netButtonr"),
+									container.SplitHorizontal(
+										container.Top(
+											container.SplitVertical(
+												container.Left(
+													container.PlaceWidget(h.netButtonr),
+													container.ID("netButtonr"),
+												),
+												container.Right(
+													container.PlaceWidget(h.netTextr),
+												),
+												container.SplitFixed(20),
+											),
 										),
-										container.Right(
-											container.PlaceWidget(h.netTextr),
+										container.Bottom(
+											container.SplitVertical(
+												container.Left(
+													container.PlaceWidget(h.ctnrButtonr),
+													container.ID("ctnrButtonr"),
+												),
+												container.Right(
+													container.PlaceWidget(h.ctnrTextr),
+												),
+												container.SplitFixed(20),
+											),
 										),
-										container.SplitFixed(20),
 									),
 								),
-								container.SplitPercent(70),
 							),
 						),
 						container.Right(
@@ -314,63 +360,80 @@ func (h *home) update(opt Option, ps *perfstat.Perfstat, paused bool, term *term
 	h.dangerText.Write(fmt.Sprintf("Danger: %d", danger), text.WriteReplace())
 
 	//BOTTLENECK
-	scc := ps.Score("bottleneck", "cpu.*")
-	drc := ps.TopCriticity(0.01, "bottleneck", "cpu.*", false)
+	scc := ps.Score("bottleneck", groupRegex("cpu"))
+	drc := ps.TopCriticity(0.01, "bottleneck", groupRegex("cpu"), false)
 	cpuButton2, _, err := subsystemBox(h.cpuButton, h.cpuText, "CPU", int(math.Round(scc*100.0)), "2", bw, bh, renderDetectionResults(drc))
 	if err != nil {
 		return err
 	}
 	rootc.Update("cpuButton", container.PlaceWidget(cpuButton2))
 
-	scm := ps.Score("bottleneck", "mem.*")
-	drm := ps.TopCriticity(0.01, "bottleneck", "mem.*", false)
+	//processes killed by the oom killer are shown along with bottlenecks
+	scm := math.Max(ps.Score("harm", groupRegex("mem")), ps.Score("bottleneck", groupRegex("mem")))
+	drm := append(ps.TopCriticity(0.01, "harm", groupRegex("mem"), false), ps.TopCriticity(0.01, "bottleneck", groupRegex("mem"), false)...)
 	memButton2, _, err := subsystemBox(h.cpuButton, h.memText, "MEM", int(math.Round(scm*100.0)), "3", bw, bh, renderDetectionResults(drm))
 	if err != nil {
 		return err
 	}
 	rootc.Update("memButton", container.PlaceWidget(memButton2))
 
-	scd := ps.Score("bottleneck", "disk.*")
-	drd := ps.TopCriticity(0.01, "bottleneck", "disk.*", false)
+	scd := ps.Score("bottleneck", groupRegex("disk"))
+	drd := ps.TopCriticity(0.01, "bottleneck", groupRegex("disk"), false)
 	diskButton2, _, err := subsystemBox(h.cpuButton, h.diskText, "DISK", int(math.Round(scd*100.0)), "4", bw, bh, renderDetectionResults(drd))
 	if err != nil {
 		return err
 	}
 	rootc.Update("diskButton", container.PlaceWidget(diskButton2))
 
-	scn := ps.Score("bottleneck", "net.*")
-	drn := ps.TopCriticity(0.01, "bottleneck", "net.*", false)
+	scn := ps.Score("bottleneck", groupRegex("net"))
+	drn := ps.TopCriticity(0.01, "bottleneck", groupRegex("net"), false)
 	netButton2, _, err := subsystemBox(h.netButton, h.netText, "NET", int(math.Round(scn*100.0)), "5", bw, bh, renderDetectionResults(drn))
 	if err != nil {
 		return err
 	}
 	rootc.Update("netButton", container.PlaceWidget(netButton2))
 
+	sct := math.Max(ps.Score("harm", groupRegex("container")), ps.Score("bottleneck", groupRegex("container")))
+	drt := append(ps.TopCriticity(0.01, "harm", groupRegex("container"), false), ps.TopCriticity(0.01, "bottleneck", groupRegex("container"), false)...)
+	ctnrButton2, _, err := subsystemBox(h.ctnrButton, h.ctnrText, "CONTAINER", int(math.Round(sct*100.0)), "6", bw, bh, renderDetectionResults(drt))
+	if err != nil {
+		return err
+	}
+	rootc.Update("ctnrButton", container.PlaceWidget(ctnrButton2))
+
 	//RISKS
-	scd = ps.Score("risk", "disk.*")
-	drd = ps.TopCriticity(0.01, "risk", "disk.*", false)
+	scd = ps.Score("risk", groupRegex("disk"))
+	drd = ps.TopCriticity(0.01, "risk", groupRegex("disk"), false)
 	diskButton2r, _, err := subsystemBox(h.diskButtonr, h.diskTextr, "DISK", int(math.Round(scd*100.0)), "4", bw, bh2, renderDetectionResults(drd))
 	if err != nil {
 		return err
 	}
 	rootc.Update("diskButtonr", container.PlaceWidget(diskButton2r))
 
-	scm = ps.Score("risk", "mem.*")
-	drm = ps.TopCriticity(0.01, "risk", "mem.*", false)
+	scm = ps.Score("risk", groupRegex("mem"))
+	drm = ps.TopCriticity(0.01, "risk", groupRegex("mem"), false)
 	memButton2r, _, err := subsystemBox(h.memButtonr, h.memTextr, "MEM", int(math.Round(scm*100.0)), "3", bw, bh2, renderDetectionResults(drm))
 	if err != nil {
 		return err
 	}
 	rootc.Update("memButtonr", container.PlaceWidget(memButton2r))
 
-	scn = ps.Score("risk", "net.*")
-	drn = ps.TopCriticity(0.01, "risk", "net.*", false)
+	scn = ps.Score("risk", groupRegex("net"))
+	drn = ps.TopCriticity(0.01, "risk", groupRegex("net"), false)
 	netButton2r, _, err := subsystemBox(h.netButtonr, h.netTextr, "NET", int(math.Round(scn*100.0)), "5", bw, bh2, renderDetectionResults(drn))
 	if err != nil {
 		return err
 	}
 	rootc.Update("netButtonr", container.PlaceWidget(netButton2r))
 
+	sct = ps.Score("risk", groupRegex("container"))
+	drt = ps.TopCriticity(0.01, "risk", groupRegex("container"), false)
+	ctnrButton2r, _, err := subsystemBox(h.ctnrButtonr, h.ctnrTextr, "CONTAINER", int(math.Round(sct*100.0)), "6", bw, bh2, renderDetectionResults(drt))
+	if err != nil {
+		return err
+	}
+	rootc.Update("ctnrButtonr", container.PlaceWidget(ctnrButton2r))
+
 	//RELATED
 	t := table.NewWriter()
 	dr := ps.TopCriticity(0.01, "", "", false)
@@ -383,7 +446,7 @@ func (h *home) update(opt Option, ps *perfstat.Perfstat, paused bool, term *term
 				pn, pv, unit := formatResPropertyValue(r00)
 				related[k] = "OK"
 				t.AppendRows([]table.Row{
-					{fmt.Sprintf("%.0f", math.Round(d0.Score*100)), r00.Name, pn, fmt.Sprintf("%s%s", pv, unit), d0.ID, d0.Typ},
+					{fmt.Sprintf("%.0f", math.Round(d0.Score*100)), resourceName(r00), pn, fmt.Sprintf("%s%s", pv, unit), d0.ID, d0.Typ},
 				})
 			}
 		}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/sirupsen/logrus"
+)
+
+//runJSON waits for enough samples, runs detections once and prints the results as a JSON document
+func runJSON(opt Option, ps *perfstat.Perfstat, started time.Time) error {
+	wait := opt.jsonDuration - time.Since(started)
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	results, err := ps.DetectNow()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(perfstat.NewJSONResults(perfstat.JSONKindSnapshot, hostname(), results))
+}
+
+//startStream prints one JSON document per line for each detection round (emit=tick)
+//or for each issue lifecycle transition (emit=transition) until ctx is done
+func startStream(ctx context.Context, opt Option, ps *perfstat.Perfstat, freq float64) error {
+	enc := json.NewEncoder(os.Stdout)
+	hn := hostname()
+
+	if opt.streamEmit == "transition" {
+		events := make(chan perfstat.IssueEvent)
+		unwatch := ps.Watch(events)
+		defer unwatch()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case e := <-events:
+				err := enc.Encode(perfstat.NewJSONEvent(hn, e))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / freq))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			dr := ps.TopCriticity(-1, "", "", false)
+			err := enc.Encode(perfstat.NewJSONResults(perfstat.JSONKindTick, hn, dr))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+//hostname name of the host, read from --host-root when set
+func hostname() string {
+	hn, err := stats.Hostname()
+	if err != nil {
+		logrus.Debugf("Couldn't get host name. err=%s", err)
+		return ""
+	}
+	return hn
+}
+
+func validateStreamOptions(opt Option) error {
+	if opt.streamFormat != "ndjson" {
+		return fmt.Errorf("--format must be ndjson")
+	}
+	if opt.streamEmit != "tick" && opt.streamEmit != "transition" {
+		return fmt.Errorf("--emit must be tick or transition")
+	}
+	return nil
+}
@@ -19,11 +19,24 @@ import (
 )
 
 type Option struct {
-	freq         float64
-	sensibility  float64
-	promBindHost string
-	promBindPort uint
-	promPath     string
+	freq           float64
+	sensibility    float64
+	promBindHost   string
+	promBindPort   uint
+	promPath       string
+	configFile     string
+	hostRoot       string
+	recordOut      string
+	recordDuration time.Duration
+	replayFile     string
+	replaySpeed    float64
+	checkDuration  time.Duration
+	checkWarn      float64
+	checkCrit      float64
+	checkGroup     string
+	jsonDuration   time.Duration
+	streamFormat   string
+	streamEmit     string
 }
 
 type screen interface {
@@ -50,6 +63,8 @@ func main() {
 
 	flag.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Changes data capture and display refresh frequency. Higher consumes more CPU. Defaults to 0 (automatic depending on sensibility)")
 	flag.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	flag.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	flag.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
 
 	promf := flag.NewFlagSet("prometheus", flag.ExitOnError)
 	promf.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Changes data capture and display refresh frequency. Higher consumes more CPU. Defaults to 1 Hz")
@@ -57,26 +72,113 @@ func main() {
 	promf.UintVar(&opt.promBindPort, "port", 8880, "Prometheus exporter port. defaults to 8880")
 	promf.StringVar(&opt.promBindHost, "host", "0.0.0.0", "Prometheus exporter bind host. defaults to 0.0.0.0")
 	promf.StringVar(&opt.promPath, "path", "/metrics", "Prometheus exporter port. defaults to /metric")
+	promf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	promf.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
+
+	recf := flag.NewFlagSet("record", flag.ExitOnError)
+	recf.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Changes data capture and display refresh frequency. Higher consumes more CPU. Defaults to 0 (automatic depending on sensibility)")
+	recf.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	recf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	recf.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
+	recf.StringVar(&opt.recordOut, "out", "", "File where samples will be recorded. Required")
+	recf.DurationVar(&opt.recordDuration, "duration", 0, "Stop recording after this duration (ex.: 2h). Defaults to 0 (record until interrupted)")
+
+	repf := flag.NewFlagSet("replay", flag.ExitOnError)
+	repf.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	repf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --sensibility")
+	repf.Float64Var(&opt.replaySpeed, "speed", 1.0, "Replay speed. 1 replays with original timing, 10 replays 10x faster. Defaults to 1")
+
+	checkf := flag.NewFlagSet("check", flag.ContinueOnError)
+	checkf.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Higher consumes more CPU. Defaults to 0 (automatic depending on sensibility)")
+	checkf.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	checkf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	checkf.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
+	checkf.DurationVar(&opt.checkDuration, "duration", 30*time.Second, "Time sampling the system before running detections. Should be at least the analysis timespan (30s for sensibility 1). Defaults to 30s")
+	checkf.Float64Var(&opt.checkWarn, "warn", 0.5, "Issue score for WARNING status (exit code 1). Defaults to 0.5")
+	checkf.Float64Var(&opt.checkCrit, "crit", 0.8, "Issue score for CRITICAL status (exit code 2). Defaults to 0.8")
+	checkf.StringVar(&opt.checkGroup, "group", "", "Only check issues from this group (cpu, mem, disk, net, container, process). Defaults to all groups")
+
+	jsonf := flag.NewFlagSet("json", flag.ExitOnError)
+	jsonf.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Higher consumes more CPU. Defaults to 0 (automatic depending on sensibility)")
+	jsonf.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	jsonf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	jsonf.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
+	jsonf.DurationVar(&opt.jsonDuration, "duration", 30*time.Second, "Time sampling the system before running detections. Should be at least the analysis timespan (30s for sensibility 1). Defaults to 30s")
+
+	streamf := flag.NewFlagSet("stream", flag.ExitOnError)
+	streamf.Float64Var(&opt.freq, "freq", 0.0, "Analysis frequency. Changes data capture and output frequency for --emit tick. Higher consumes more CPU. Defaults to 0 (automatic depending on sensibility)")
+	streamf.Float64Var(&opt.sensibility, "sensibility", 1.0, "Lower values (ex.: 0.2) means larger timespan in analysis, leading to more accurate results but slower responses. Higher values (ex.: 5) means short time analysis but may lead to false positives. Defaults to 1.0 which means detecting a continuous 100% CPU in 30s")
+	streamf.StringVar(&opt.configFile, "config", "", "YAML file with detector options. Values in this file override the ones derived from --freq and --sensibility")
+	streamf.StringVar(&opt.hostRoot, "host-root", "", "Dir where host root filesystem is mounted when running inside a container (ex.: /host). Stats will be read from <dir>/proc, <dir>/sys and <dir>/etc")
+	streamf.StringVar(&opt.streamFormat, "format", "ndjson", "Output format. Only ndjson (one JSON document per line) is supported. Defaults to ndjson")
+	streamf.StringVar(&opt.streamEmit, "emit", "tick", "When to output a line. 'tick' outputs all detection results on each analysis round; 'transition' outputs issue lifecycle events (opened, escalated, de-escalated, resolved). Defaults to tick")
 
 	logrus.SetLevel(loglevel)
 
-	prom := false
+	mode := "ui"
 	if len(os.Args) > 1 && os.Args[1] == "prometheus" {
+		mode = "prometheus"
 		err := promf.Parse(os.Args[2:])
 		if err != nil {
-			panic(err)
+			exitInvalidOptions(mode, err.Error())
+		}
+	} else if len(os.Args) > 1 && os.Args[1] == "record" {
+		mode = "record"
+		err := recf.Parse(os.Args[2:])
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+		if opt.recordOut == "" {
+			exitInvalidOptions(mode, "--out is required")
+		}
+	} else if len(os.Args) > 1 && os.Args[1] == "replay" {
+		mode = "replay"
+		err := repf.Parse(os.Args[2:])
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+		if repf.NArg() != 1 {
+			exitInvalidOptions(mode, "usage: perfstat replay [--speed 1] [--sensibility 1] [--config file] file")
+		}
+		if opt.replaySpeed < 0.1 || opt.replaySpeed > 100 {
+			exitInvalidOptions(mode, "--speed must be between 0.1 and 100")
+		}
+		opt.replayFile = repf.Arg(0)
+	} else if len(os.Args) > 1 && os.Args[1] == "check" {
+		mode = "check"
+		err := checkf.Parse(os.Args[2:])
+		if err != nil {
+			os.Exit(checkUnknown)
+		}
+		if opt.checkWarn > opt.checkCrit {
+			exitInvalidOptions(mode, "--warn must not be greater than --crit")
+		}
+	} else if len(os.Args) > 1 && os.Args[1] == "json" {
+		mode = "json"
+		err := jsonf.Parse(os.Args[2:])
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+	} else if len(os.Args) > 1 && os.Args[1] == "stream" {
+		mode = "stream"
+		err := streamf.Parse(os.Args[2:])
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+		err = validateStreamOptions(opt)
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
 		}
-		prom = true
 	} else {
 		flag.Parse()
 	}
 
 	if opt.freq != 0.0 && (opt.freq > 5 || opt.freq < 0.05) {
-		panic("--freq must be between 0.05 and 5")
+		exitInvalidOptions(mode, "--freq must be between 0.05 and 5")
 	}
 
 	if opt.sensibility > 30 || opt.sensibility < 0.01 {
-		panic("--sensibility must be between 0.01 and 30")
+		exitInvalidOptions(mode, "--sensibility must be between 0.01 and 30")
 	}
 
 	logrus.Debugf("Initializing Perfstat engine")
@@ -95,14 +197,85 @@ func main() {
 		opt2.DefaultSampleFreq = (0.1657*opt.sensibility + 0.02834)
 	}
 
+	if opt.hostRoot != "" {
+		opt2.HostRoot = opt.hostRoot
+	}
+
+	if opt.configFile != "" {
+		var err error
+		opt2, err = detectors.LoadOptionsFrom(opt.configFile, opt2)
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+		if opt2.Loglevel != "" {
+			loglevel, err = logrus.ParseLevel(opt2.Loglevel)
+			if err != nil {
+				exitInvalidOptions(mode, fmt.Sprintf("Invalid config file %s. err=logLevel: %s", opt.configFile, err))
+			}
+			logrus.SetLevel(loglevel)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if mode == "record" {
+		err := startRecord(ctx, opt, opt2)
+		if err != nil {
+			fmt.Printf("Error recording. err=%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode == "replay" {
+		var err error
+		opt2, err = replayOptions(opt.replayFile, opt.replaySpeed, opt2)
+		if err != nil {
+			fmt.Printf("Error loading recording %s. err=%s\n", opt.replayFile, err)
+			os.Exit(1)
+		}
+	}
+
+	started := time.Now()
 	ps = perfstat.Start(ctx, opt2)
 	ps.SetLogLevel(loglevel)
 	// time.Sleep(6 * time.Second)
 
-	if !prom {
+	if mode == "check" {
+		code := runCheck(opt, ps, started)
+		cancel()
+		os.Exit(code)
+	}
+
+	if mode == "json" {
+		err := runJSON(opt, ps, started)
+		if err != nil {
+			fmt.Printf("Error generating JSON. err=%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//recorded sessions are not notified, as the issues are not happening now
+	if len(opt2.Webhooks) > 0 && mode != "replay" {
+		_, err := perfstat.StartNotifier(ctx, ps, opt2.Webhooks)
+		if err != nil {
+			exitInvalidOptions(mode, err.Error())
+		}
+		logrus.Debugf("Sending notifications to %d webhooks", len(opt2.Webhooks))
+	}
+
+	if mode == "stream" {
+		err := startStream(ctx, opt, ps, opt2.DefaultSampleFreq)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error streaming JSON. err=%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if mode != "prometheus" {
 		startUI(ctx, cancel, math.Round(opt2.DefaultSampleFreq*2.0)+1.0)
 	} else {
 		logrus.Debugf("Starting Prometheus Exporter")
@@ -144,6 +317,8 @@ func startUI(ctx context.Context, cancel context.CancelFunc, screenFreq float64)
 			showScreen("disk")
 		} else if k.Key == 53 {
 			showScreen("net")
+		} else if k.Key == 54 {
+			showScreen("container")
 		}
 		updateScreens()
 	}
@@ -184,6 +359,12 @@ func startUI(ctx context.Context, cancel context.CancelFunc, screenFreq float64)
 	}
 	screens["net"] = d
 
+	d, err = newDetails("container", opt, ps)
+	if err != nil {
+		panic(fmt.Sprintf("Error preparing screen. err=%s", err))
+	}
+	screens["container"] = d
+
 	showScreen("home")
 
 	paused = false
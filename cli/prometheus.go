@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/detectors"
 	"github.com/flaviostutz/signalutils"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -54,9 +56,19 @@ func startPrometheus(ctx context.Context, opt Option, ps *perfstat.Perfstat) {
 		"resource_property_name",
 	})
 
+	diskFullETAGauge := promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "disk_full_eta_seconds",
+		Help: "Estimated time until partitions run out of free space or inodes at the current rate",
+	}, []string{
+		"host",
+		"partition",
+		"resource",
+	})
+
 	//setup prometheus metrics http server
 	router := mux.NewRouter()
 	router.Handle(opt.promPath, promhttp.Handler())
+	registerAPI(ctx, router, ps)
 
 	listen := fmt.Sprintf("%s:%d", opt.promBindHost, opt.promBindPort)
 	listenPort, err := net.Listen("tcp", listen)
@@ -87,19 +99,38 @@ func startPrometheus(ctx context.Context, opt Option, ps *perfstat.Perfstat) {
 		genMetrics(dangerGauge, info, "bottleneck", "mem")
 		genMetrics(dangerGauge, info, "bottleneck", "disk")
 		genMetrics(dangerGauge, info, "bottleneck", "net")
+		genMetrics(dangerGauge, info, "bottleneck", "container")
+		genMetrics(dangerGauge, info, "bottleneck", "process")
 		genMetrics(dangerGauge, info, "risk", "mem")
 		genMetrics(dangerGauge, info, "risk", "disk")
 		genMetrics(dangerGauge, info, "risk", "net")
+		genMetrics(dangerGauge, info, "risk", "container")
+		genMetrics(dangerGauge, info, "risk", "process")
+		genMetrics(dangerGauge, info, "harm", "mem")
+		genMetrics(dangerGauge, info, "harm", "container")
+		genMetrics(dangerGauge, info, "harm", "process")
 
 		//DETECTIONS
 		dr := ps.TopCriticity(-1, "", "", false)
 		for _, d := range dr {
 			relName := ""
 			if len(d.Related) > 0 {
-				relName = d.Related[0].Name
+				relName = resourceName(d.Related[0])
+			}
+			issuesGauge.WithLabelValues(info.Hostname, d.Typ, detectors.GroupFromID(d.ID), fmt.Sprintf("%s", d.ID), d.Res.Name, d.Res.PropertyName, relName).Set(d.Score)
+			issueResourceGauge.WithLabelValues(info.Hostname, d.Typ, detectors.GroupFromID(d.ID), fmt.Sprintf("%s", d.ID), d.Res.Name, d.Res.PropertyName).Set(d.Res.PropertyValue)
+		}
+
+		//DISK FULL FORECAST
+		//only partitions that are being filled up are exposed
+		diskFullETAGauge.Reset()
+		for _, d := range ps.TopCriticity(0, "risk", "^disk-(space|inodes)-full-eta$", false) {
+			if d.Res.PropertyValue < 0 {
+				continue
 			}
-			issuesGauge.WithLabelValues(info.Hostname, d.Typ, groupFromID(d.ID), fmt.Sprintf("%s", d.ID), d.Res.Name, d.Res.PropertyName, relName).Set(d.Score)
-			issueResourceGauge.WithLabelValues(info.Hostname, d.Typ, groupFromID(d.ID), fmt.Sprintf("%s", d.ID), d.Res.Name, d.Res.PropertyName).Set(d.Res.PropertyValue)
+			partition := strings.TrimPrefix(d.Res.Name, "partition:")
+			resource := strings.TrimSuffix(d.Res.PropertyName, "-full-eta-seconds")
+			diskFullETAGauge.WithLabelValues(info.Hostname, partition, resource).Set(d.Res.PropertyValue)
 		}
 
 		return nil
@@ -112,5 +143,5 @@ func startPrometheus(ctx context.Context, opt Option, ps *perfstat.Perfstat) {
 }
 
 func genMetrics(g *prometheus.GaugeVec, info *host.InfoStat, typ string, group string) {
-	g.WithLabelValues(info.Hostname, typ, group).Set(ps.Score(typ, fmt.Sprintf("%s.*", group)))
+	g.WithLabelValues(info.Hostname, typ, group).Set(ps.Score(typ, groupRegex(group)))
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/flaviostutz/perfstat"
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/sirupsen/logrus"
+)
+
+//startRecord samples the system and writes every sample to opt.recordOut until
+//interrupted or opt.recordDuration is reached
+func startRecord(ctx context.Context, opt Option, opt2 detectors.Options) error {
+	f, err := os.Create(opt.recordOut)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rs, err := stats.NewRecordingSource(nil, f, opt2.DefaultSampleFreq)
+	if err != nil {
+		return err
+	}
+	opt2.Source = rs
+
+	ctx, cancel := context.WithCancel(ctx)
+	ps = perfstat.Start(ctx, opt2)
+
+	fmt.Printf("Recording to %s at %.2f Hz. Press Ctrl+C to stop\n", opt.recordOut, opt2.DefaultSampleFreq)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	var timeout <-chan time.Time
+	if opt.recordDuration > 0 {
+		timeout = time.After(opt.recordDuration)
+	}
+
+	select {
+	case <-sigs:
+		logrus.Debugf("Recording interrupted")
+	case <-timeout:
+		logrus.Debugf("Recording duration reached")
+	case <-ctx.Done():
+	}
+	cancel()
+
+	err = rs.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recording saved to %s\n", opt.recordOut)
+	return nil
+}
+
+//replayOptions prepares options for replaying a recorded session from file
+func replayOptions(file string, speed float64, opt2 detectors.Options) (detectors.Options, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return opt2, err
+	}
+	defer f.Close()
+
+	rec, err := stats.ReadRecording(f)
+	if err != nil {
+		return opt2, err
+	}
+	logrus.Debugf("Replaying session recorded at %s with %d cpu samples", rec.Header.Start, len(rec.Timeline.CPU))
+
+	opt2.DefaultSampleFreq = rec.Header.SampleFreq
+	opt2 = opt2.Accelerated(speed)
+	opt2.HostRoot = ""
+	opt2.Source = stats.NewScriptedSource(rec.Timeline.Accelerated(speed))
+	return opt2, nil
+}
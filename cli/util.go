@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/flaviostutz/perfstat"
@@ -80,6 +82,15 @@ func formatResPropertyValue(res detectors.Resource) (formattedName string, forma
 	return formattedName, formattedValue, unit2
 }
 
+//resourceName resource name followed by the container it runs in, if any. ex.: java[2345] (billing-api)
+func resourceName(res detectors.Resource) string {
+	cn := res.Labels["container_name"]
+	if cn == "" {
+		return res.Name
+	}
+	return fmt.Sprintf("%s (%s)", res.Name, cn)
+}
+
 func formatValueUnit(value float64, unit string) (v string, u string) {
 	// if true {
 	// 	return fmt.Sprintf("%.2f", value), unit
@@ -112,16 +123,33 @@ func formatValueUnit(value float64, unit string) (v string, u string) {
 	return formattedValue, unit
 }
 
+//dangerLevel average of the scores of all issue groups, from 0 to 100
+//groups are taken from the registered detectors, so that every group is accounted for
 func dangerLevel(ps *perfstat.Perfstat) int {
+	groups := issueGroups()
+	if len(groups) == 0 {
+		return 0
+	}
 	os := 0.0
-	os = ps.Score("bottleneck", "cpu.*")
-	os = os + ps.Score("bottleneck", "mem.*")
-	os = os + ps.Score("bottleneck", "disk.*")
-	os = os + ps.Score("bottleneck", "net.*")
-	os = os + ps.Score("risk", "mem.*")
-	os = os + ps.Score("risk", "disk.*")
-	os = os + ps.Score("risk", "net.*")
-	return int(math.Round((os / 7.0) * 100))
+	for _, g := range groups {
+		os = os + ps.Score("", groupRegex(g))
+	}
+	return int(math.Round((os / float64(len(groups))) * 100))
+}
+
+//issueGroups groups of the issues reported by the registered detectors, sorted by name
+func issueGroups() []string {
+	seen := make(map[string]bool)
+	groups := make([]string, 0)
+	for _, id := range detectors.DetectorIDs {
+		g := detectors.GroupFromID(id)
+		if !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	sort.Strings(groups)
+	return groups
 }
 
 func resolveSparkline(sl *sparkline.SparkLine, value int, ts *signalutils.Timeseries, label string, colorize bool) (*sparkline.SparkLine, error) {
@@ -154,10 +182,17 @@ func resolveSparkline(sl *sparkline.SparkLine, value int, ts *signalutils.Timese
 	return sl, err
 }
 
-func groupFromID(id string) string {
-	idx := strings.Index(id, "-")
-	if idx == -1 {
-		return "ERROR"
+//groupRegex regex that matches IDs of issues from a group. Matches all issues if group is ""
+//IDs are taken from the registered detectors, so that groups are the same as in detectors.GroupFromID
+func groupRegex(group string) string {
+	if group == "" {
+		return ""
+	}
+	ids := make([]string, 0)
+	for _, id := range detectors.DetectorIDs {
+		if detectors.GroupFromID(id) == group {
+			ids = append(ids, regexp.QuoteMeta(id))
+		}
 	}
-	return id[:idx]
+	return fmt.Sprintf("^(%s)$", strings.Join(ids, "|"))
 }
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueGroups(t *testing.T) {
+	assert.Equal(t, []string{"container", "cpu", "disk", "mem", "net", "process"}, issueGroups())
+}
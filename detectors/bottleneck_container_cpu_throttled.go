@@ -0,0 +1,67 @@
+package detectors
+
+import (
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		from := time.Now().Add(-opt.CPULoadAvgDuration)
+
+		issues := make([]DetectionResult, 0)
+
+		for _, cm := range ActiveStats.CgroupStats.Cgroups {
+			//skip containers that are gone
+			if cm.ContainerID == "" || cm.LastSeen.Before(from) {
+				continue
+			}
+			r := DetectionResult{
+				Typ:  "bottleneck",
+				ID:   "container-cpu-throttled",
+				When: time.Now(),
+			}
+
+			//share of cpu quota periods in which the container was throttled
+			periods, ok := cm.NrPeriods.Rate(opt.CPULoadAvgDuration)
+			if !ok {
+				r.Res = containerResource(cm, "cpu-throttled-perc", 0)
+				r.Message = notEnoughDataMessage(opt.CPULoadAvgDuration)
+				r.Score = -1
+				issues = append(issues, r)
+				continue
+			}
+			throttled, _ := cm.NrThrottled.Rate(opt.CPULoadAvgDuration)
+			throttledPerc := 0.0
+			if periods > 0 {
+				throttledPerc = throttled / periods
+			}
+
+			r.Res = containerResource(cm, "cpu-throttled-perc", throttledPerc)
+			r.Score = criticityScore(throttledPerc, opt.rangeFor(r.ID, opt.ContainerThrottledRange))
+
+			if r.Score > 0 {
+				//get container cpu eaters
+				r.Related = make([]Resource, 0)
+				for _, proc := range containerProcesses(ActiveStats.ProcessStats.TopCPULoad(), cm.ContainerID) {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+						break
+					}
+					pu, ok := stats.TimeLoadPerc(&proc.CPUTimes.User, opt.CPULoadAvgDuration)
+					if !ok {
+						logrus.Tracef("Couldn't get cpu load for pid %d", proc.Pid)
+						continue
+					}
+					ps, _ := stats.TimeLoadPerc(&proc.CPUTimes.System, opt.CPULoadAvgDuration)
+					r.Related = append(r.Related, processResource(proc, "cpu-load-perc", pu+ps))
+				}
+			}
+			issues = append(issues, r)
+		}
+
+		return issues
+	}, "container-cpu-throttled")
+}
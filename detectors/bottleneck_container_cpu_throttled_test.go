@@ -0,0 +1,57 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerCPUThrottled(t *testing.T) {
+	id := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	path := "/system.slice/docker-" + id + ".scope"
+
+	runScriptedCases(t, []scriptedCase{{
+		//container using a full cpu, throttled in 60% of the periods
+		name: "throttled",
+		sample: func(i int, tl *stats.Timeline) {
+			v := float64(i) * 0.1
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				{Pid: 123, Name: "java", Cgroup: path, Times: &cpu.TimesStat{User: v}},
+				{Pid: 456, Name: "java", Times: &cpu.TimesStat{User: v / 2}},
+			})
+			tl.Cgroups = append(tl.Cgroups, []stats.CgroupSample{{
+				Path:          path,
+				CPUUsageUsec:  uint64(i) * 100000,
+				NrPeriods:     uint64(i) * 10,
+				NrThrottled:   uint64(i) * 6,
+				MemoryCurrent: 500,
+				MemoryMax:     1000,
+			}})
+		},
+		options: func(opt *Options) {
+			opt.CPULoadAvgDuration = 1 * time.Second
+			opt.MemAvgDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			th, _ := findResult(results, "container-cpu-throttled", "0123456789ab")
+			assert.Equal(t, "bottleneck", th.Typ)
+			assert.InDelta(t, 0.6, th.Res.PropertyValue, 0.05)
+			assert.InDelta(t, 1.0, th.Score, 0.01)
+			assert.Equal(t, "0123456789ab", th.Res.Name)
+			assert.Equal(t, id, th.Res.Labels["container_id"])
+			if assert.Equal(t, 1, len(th.Related)) {
+				assert.Equal(t, "java[123]", th.Related[0].Name)
+				assert.Equal(t, path, th.Related[0].Labels["cgroup"])
+			}
+
+			ml, _ := findResult(results, "container-mem-near-limit", "0123456789ab")
+			assert.Equal(t, 0.0, ml.Score)
+			assert.InDelta(t, 0.5, ml.Res.PropertyValue, 0.01)
+			oom, _ := findResult(results, "container-oom-kill", "0123456789ab")
+			assert.Equal(t, 0.0, oom.Score)
+		},
+	}})
+}
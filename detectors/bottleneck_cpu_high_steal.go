@@ -0,0 +1,64 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "cpu-high-steal",
+			When: time.Now(),
+		}
+
+		steal, ok := stats.TimeLoadPerc(&ActiveStats.CPUStats.Total.Steal, opt.CPULoadAvgDuration)
+		if !ok {
+			r.Message = notEnoughDataMessage(opt.CPULoadAvgDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+
+		r.Res = Resource{
+			Typ:           "cpu",
+			Name:          "cpu:all",
+			PropertyName:  "steal-perc",
+			PropertyValue: steal,
+		}
+
+		stealRange := opt.rangeFor(r.ID, opt.HighCPUStealPercRange)
+		r.Score = criticityScore(steal, stealRange)
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		r.Message = fmt.Sprintf("The hypervisor is giving %.0f%% of the cpu time of this guest to other VMs. The cause is outside this host: noisy neighbors on the same physical host or exhausted cpu credits", steal*100)
+
+		//cpus with most steal
+		r.Related = make([]Resource, 0)
+		for cpui, cpu := range ActiveStats.CPUStats.CPU {
+			cs, ok := stats.TimeLoadPerc(&cpu.Steal, opt.CPULoadAvgDuration)
+			if !ok || cs < stealRange[0] {
+				continue
+			}
+			r.Related = append(r.Related, Resource{
+				Typ:           "cpu",
+				Name:          fmt.Sprintf("cpu:%d", cpui),
+				PropertyName:  "steal-perc",
+				PropertyValue: cs,
+			})
+		}
+		sort.Slice(r.Related, func(i, j int) bool {
+			return r.Related[i].PropertyValue > r.Related[j].PropertyValue
+		})
+		if len(r.Related) > opt.maxRelated(r.ID, 4) {
+			r.Related = r.Related[:opt.maxRelated(r.ID, 4)]
+		}
+
+		return []DetectionResult{r}
+	}, "cpu-high-steal")
+}
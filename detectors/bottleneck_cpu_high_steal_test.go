@@ -0,0 +1,41 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUHighSteal(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//cpu0 with 30% and cpu1 with 2% of its time stolen by the hypervisor
+		name: "steal",
+		sample: func(i int, tl *stats.Timeline) {
+			v := float64(i) * 0.1
+			tl.CPU[i] = stats.CPUSample{
+				Total: cpu.TimesStat{User: v * 1.68, Steal: v * 0.32},
+				CPUs: []cpu.TimesStat{
+					{CPU: "cpu0", User: v * 0.7, Steal: v * 0.3},
+					{CPU: "cpu1", User: v * 0.98, Steal: v * 0.02},
+				},
+			}
+		},
+		options: func(opt *Options) {
+			opt.CPULoadAvgDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			st, _ := findResult(results, "cpu-high-steal", "")
+			assert.Equal(t, "steal-perc", st.Res.PropertyName)
+			assert.InDelta(t, 0.16, st.Res.PropertyValue, 0.01)
+			assert.InDelta(t, 0.73, st.Score, 0.05)
+			assert.Contains(t, st.Message, "outside this host")
+			if assert.Equal(t, 1, len(st.Related)) {
+				assert.Equal(t, "cpu:0", st.Related[0].Name)
+				assert.InDelta(t, 0.3, st.Related[0].PropertyValue, 0.01)
+			}
+		},
+	}})
+}
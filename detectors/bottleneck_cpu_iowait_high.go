@@ -31,7 +31,7 @@ func init() {
 			PropertyValue: iowait,
 		}
 
-		r.Score = criticityScore(iowait, opt.HighCPUWaitPercRange)
+		r.Score = criticityScore(iowait, opt.rangeFor(r.ID, opt.HighCPUWaitPercRange))
 
 		if r.Score == 0 {
 			return []DetectionResult{r}
@@ -40,7 +40,7 @@ func init() {
 		//get most waited processes
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopCPUIOWait() {
-			if len(r.Related) >= 3 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 				break
 			}
 			iw, ok := stats.TimeLoadPerc(&proc.CPUTimes.IOWait, opt.CPULoadAvgDuration)
@@ -48,12 +48,7 @@ func init() {
 				logrus.Tracef("Couldn't get iowait time for pid %d", proc.Pid)
 				continue
 			}
-			res := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "cpu-iowait-perc",
-				PropertyValue: iw,
-			}
+			res := processResource(proc, "cpu-iowait-perc", iw)
 			r.Related = append(r.Related, res)
 		}
 
@@ -185,5 +180,5 @@ func init() {
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "cpu-high-iowait")
 }
@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/flaviostutz/perfstat/stats"
@@ -32,7 +31,7 @@ func init() {
 			PropertyValue: load,
 		}
 
-		r.Score = criticityScore(load, opt.HighCPUPercRange)
+		r.Score = criticityScore(load, opt.rangeFor(r.ID, opt.HighCPUPercRange))
 		logrus.Tracef("cpu-low load=%.2f criticityScore=%.2f", load, r.Score)
 		if r.Score == 0 {
 			return []DetectionResult{r}
@@ -41,17 +40,12 @@ func init() {
 		//get hungry processes
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopCPULoad() {
-			if len(r.Related) >= 3 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 				break
 			}
 			ps, _ := stats.TimeLoadPerc(&proc.CPUTimes.System, opt.CPULoadAvgDuration)
 			pu, _ := stats.TimeLoadPerc(&proc.CPUTimes.User, opt.CPULoadAvgDuration)
-			res := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "cpu-load-perc",
-				PropertyValue: pu + ps,
-			}
+			res := processResource(proc, "cpu-load-perc", pu+ps)
 			r.Related = append(r.Related, res)
 		}
 
@@ -68,5 +62,5 @@ func init() {
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "cpu-low-idle")
 }
@@ -0,0 +1,95 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "cpu-runqueue-high",
+			When: time.Now(),
+		}
+
+		to := time.Now()
+		from := to.Add(-opt.CPULoadAvgDuration)
+
+		ls := ActiveStats.LoadStats
+		running, ok1 := ls.ProcsRunning.Avg(from, to)
+		blocked, ok2 := ls.ProcsBlocked.Avg(from, to)
+		ctxsw, ok3 := ls.CtxSwitches.Rate(opt.CPULoadAvgDuration)
+		intr, ok4 := ls.Interrupts.Rate(opt.CPULoadAvgDuration)
+		cpus := len(ActiveStats.CPUStats.CPU)
+		if !ok1 || !ok2 || !ok3 || !ok4 || cpus == 0 {
+			r.Message = notEnoughDataMessage(opt.CPULoadAvgDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+
+		perCPU := running / float64(cpus)
+		r.Res = Resource{
+			Typ:           "cpu",
+			Name:          "cpu:all",
+			PropertyName:  "runnable-per-cpu-count",
+			PropertyValue: perCPU,
+		}
+
+		r.Score = criticityScore(perCPU, opt.rangeFor(r.ID, opt.RunQueueRange))
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		r.Message = fmt.Sprintf("%.1f runnable tasks per cpu (%d cpus). load average %.2f %.2f %.2f. %.0f context switches/s, %.0f interrupts/s, %.1f tasks blocked on io", perCPU, cpus, lastValue(&ls.Load1), lastValue(&ls.Load5), lastValue(&ls.Load15), ctxsw, intr, blocked)
+		r.Related = []Resource{{
+			Typ:           "cpu",
+			Name:          "cpu:all",
+			PropertyName:  "context-switches-ps",
+			PropertyValue: ctxsw,
+		}}
+
+		//processes that are preempted the most are competing for cpus. processes with most
+		//threads or that switch voluntarily the most (waiting for locks or io) may be flooding the run queue
+		seen := make(map[int32]bool)
+		for _, rank := range []string{"involuntary", "threads", "voluntary"} {
+			procs := ActiveStats.ProcessStats.TopThreads()
+			if rank != "threads" {
+				procs = ActiveStats.ProcessStats.TopCtxSwitchRate(rank == "voluntary")
+			}
+			added := 0
+			for _, proc := range procs {
+				if added >= opt.maxRelated(r.ID, 2) {
+					break
+				}
+				if time.Now().Sub(proc.LastSeen) > processSamplePeriod(opt) {
+					continue
+				}
+				if seen[proc.Pid] {
+					continue
+				}
+				seen[proc.Pid] = true
+				added = added + 1
+
+				vol, _ := proc.VoluntaryCtxSwitches.Rate(opt.CPULoadAvgDuration)
+				invol, _ := proc.InvoluntaryCtxSwitches.Rate(opt.CPULoadAvgDuration)
+				threads := lastValue(&proc.NumThreads)
+				res := processResource(proc, "cpu-involuntary-ctxsw-ps", invol)
+				if rank == "threads" {
+					res = processResource(proc, "threads-count", threads)
+				} else if rank == "voluntary" {
+					res = processResource(proc, "cpu-voluntary-ctxsw-ps", vol)
+				}
+				if res.Labels == nil {
+					res.Labels = make(map[string]string)
+				}
+				res.Labels["threads"] = fmt.Sprintf("%.0f", threads)
+				res.Labels["voluntary_ctxsw_ps"] = fmt.Sprintf("%.1f", vol)
+				res.Labels["involuntary_ctxsw_ps"] = fmt.Sprintf("%.1f", invol)
+				r.Related = append(r.Related, res)
+			}
+		}
+
+		return []DetectionResult{r}
+	}, "cpu-runqueue-high")
+}
@@ -0,0 +1,72 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPURunQueueHigh(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//5 runnable tasks per cpu on a 2 cpus host
+		//java has most threads and is preempted the most. redis switches voluntarily the most
+		name: "runqueue",
+		sample: func(i int, tl *stats.Timeline) {
+			v := float64(i) * 0.1
+			tl.CPU[i] = stats.CPUSample{
+				Total: cpu.TimesStat{User: v * 2},
+				CPUs: []cpu.TimesStat{
+					{CPU: "cpu0", User: v},
+					{CPU: "cpu1", User: v},
+				},
+			}
+			tl.Load = append(tl.Load, stats.LoadSample{
+				Load1:        9.5,
+				Load5:        6,
+				Load15:       3,
+				ProcsRunning: 10,
+				ProcsBlocked: 1,
+				CtxSwitches:  uint64(i) * 5000,
+				Interrupts:   uint64(i) * 1000,
+			})
+			javaThreads := int32(300)
+			redisThreads := int32(4)
+			nginxThreads := int32(10)
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				{Pid: 100, Name: "java", NumThreads: &javaThreads, CtxSwitches: &process.NumCtxSwitchesStat{Voluntary: int64(i) * 10, Involuntary: int64(i) * 300}},
+				{Pid: 200, Name: "redis", NumThreads: &redisThreads, CtxSwitches: &process.NumCtxSwitchesStat{Voluntary: int64(i) * 800, Involuntary: int64(i) * 5}},
+				{Pid: 300, Name: "nginx", NumThreads: &nginxThreads, CtxSwitches: &process.NumCtxSwitchesStat{Voluntary: int64(i) * 20, Involuntary: int64(i) * 100}},
+			})
+		},
+		options: func(opt *Options) {
+			opt.CPULoadAvgDuration = 1 * time.Second
+			opt.Detectors["cpu-runqueue-high"] = DetectorOptions{MaxRelated: 1}
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			rq, _ := findResult(results, "cpu-runqueue-high", "")
+			assert.Equal(t, 5.0, rq.Res.PropertyValue)
+			assert.Equal(t, 1.0, rq.Score)
+			assert.Contains(t, rq.Message, "load average 9.50 6.00 3.00")
+			if assert.Equal(t, 4, len(rq.Related)) {
+				assert.Equal(t, "context-switches-ps", rq.Related[0].PropertyName)
+				assert.InDelta(t, 50000, rq.Related[0].PropertyValue, 5000)
+
+				assert.Equal(t, "java[100]", rq.Related[1].Name)
+				assert.Equal(t, "cpu-involuntary-ctxsw-ps", rq.Related[1].PropertyName)
+				assert.InDelta(t, 3000, rq.Related[1].PropertyValue, 300)
+				assert.Equal(t, "300", rq.Related[1].Labels["threads"])
+
+				//java was already related
+				assert.Equal(t, "nginx[300]", rq.Related[2].Name)
+				assert.Equal(t, "threads-count", rq.Related[2].PropertyName)
+
+				assert.Equal(t, "redis[200]", rq.Related[3].Name)
+				assert.Equal(t, "cpu-voluntary-ctxsw-ps", rq.Related[3].PropertyName)
+			}
+		},
+	}})
+}
@@ -28,7 +28,7 @@ func init() {
 			}
 
 			load := 1.0 - idle
-			r.Score = criticityScore(load, opt.HighCPUPercRange)
+			r.Score = criticityScore(load, opt.rangeFor(r.ID, opt.HighCPUPercRange))
 
 			r.Res = Resource{
 				Typ:           "cpu",
@@ -41,17 +41,12 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopCPULoad() {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					ps, _ := stats.TimeLoadPerc(&proc.CPUTimes.System, opt.CPULoadAvgDuration)
 					pu, _ := stats.TimeLoadPerc(&proc.CPUTimes.User, opt.CPULoadAvgDuration)
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "cpu-all-load-perc",
-						PropertyValue: pu + ps,
-					}
+					res := processResource(proc, "cpu-all-load-perc", pu+ps)
 					r.Related = append(r.Related, res)
 				}
 			}
@@ -59,5 +54,5 @@ func init() {
 			issues = append(issues, r)
 		}
 		return issues
-	})
+	}, "cpu-single-low-idle")
 }
@@ -0,0 +1,106 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/flaviostutz/signalutils"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		issues := make([]DetectionResult, 0)
+
+		for dname, disk := range ActiveStats.DiskStats.Disks {
+			r := DetectionResult{
+				Typ:  "bottleneck",
+				ID:   "disk-high-latency",
+				When: time.Now(),
+			}
+
+			reads, rok := timeDelta(&disk.ReadCount.Timeseries, opt.IORateLoadDuration)
+			readTime, rtok := timeDelta(&disk.ReadTime, opt.IORateLoadDuration)
+			writes, wok := timeDelta(&disk.WriteCount.Timeseries, opt.IORateLoadDuration)
+			writeTime, wtok := timeDelta(&disk.WriteTime, opt.IORateLoadDuration)
+			//weighted io time in ms per second is the average number of ios in flight, as aqu-sz in iostat
+			weightedPerSec, qok := stats.TimeLoadPerc(&disk.WeightedIO, opt.IORateLoadDuration)
+			if !rok || !rtok || !wok || !wtok || !qok {
+				r.Res = Resource{Typ: "disk", Name: fmt.Sprintf("disk:%s", dname)}
+				r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+				r.Score = -1
+				issues = append(issues, r)
+				continue
+			}
+
+			queue := weightedPerSec / 1000
+
+			//few ios are not enough for calculating the latency reliably
+			readAwait := 0.0
+			if reads >= 10 {
+				readAwait = readTime / reads
+			}
+			writeAwait := 0.0
+			if writes >= 10 {
+				writeAwait = writeTime / writes
+			}
+
+			latencyRange := opt.rangeFor(r.ID, opt.diskLatencyRange(disk.Class))
+			r.Res = Resource{
+				Typ:           "disk",
+				Name:          fmt.Sprintf("disk:%s", dname),
+				PropertyName:  "read-await-ms",
+				PropertyValue: readAwait,
+			}
+			r.Score = criticityScore(readAwait, latencyRange)
+			ws := criticityScore(writeAwait, latencyRange)
+			if ws > r.Score || (ws == r.Score && writeAwait > readAwait) {
+				r.Res.PropertyName = "write-await-ms"
+				r.Res.PropertyValue = writeAwait
+				r.Score = ws
+			}
+
+			if r.Score > 0 {
+				class := disk.Class
+				if class == "" {
+					class = "unknown"
+				}
+				r.Message = fmt.Sprintf("IOs taking %.1fms (read) and %.1fms (write) on average on %s disk. Avg queue depth %.1f", readAwait, writeAwait, class, queue)
+				r.Related = []Resource{{
+					Typ:           "disk",
+					Name:          fmt.Sprintf("disk:%s", dname),
+					PropertyName:  "queue-depth-avg",
+					PropertyValue: queue,
+				}}
+			}
+			issues = append(issues, r)
+		}
+
+		return issues
+	}, "disk-high-latency")
+}
+
+//diskLatencyRange await range in ms for a disk class. Disks of unknown class use the hdd range
+func (o *Options) diskLatencyRange(class string) [2]float64 {
+	switch class {
+	case stats.DiskClassNVMe:
+		return o.DiskLatencyNVMeRange
+	case stats.DiskClassSSD:
+		return o.DiskLatencySSDRange
+	}
+	return o.DiskLatencyHDDRange
+}
+
+//timeDelta increase of a cumulative value during the last timeSpan
+func timeDelta(ts *signalutils.Timeseries, timeSpan time.Duration) (float64, bool) {
+	v1, ok := ts.Get(time.Now().Add(-timeSpan))
+	if !ok {
+		return 0, false
+	}
+	v2, ok := ts.Last()
+	if !ok {
+		return 0, false
+	}
+	return v2.Value - v1.Value, true
+}
@@ -0,0 +1,52 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskHighLatency(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//100 reads/s taking 10ms and 100 writes/s taking 50ms
+		name: "await",
+		sample: func(i int, tl *stats.Timeline) {
+			c := disk.IOCountersStat{
+				ReadCount:  uint64(i) * 10,
+				ReadTime:   uint64(i) * 100,
+				WriteCount: uint64(i) * 10,
+				WriteTime:  uint64(i) * 500,
+				WeightedIO: uint64(i) * 600,
+			}
+			//unknown class (hdd ranges) and nvme
+			c1 := c
+			c1.Name = "xvdq"
+			c2 := c
+			c2.Name = "nvme9n1"
+			c2.WriteTime = 0
+			tl.DiskIO = append(tl.DiskIO, map[string]disk.IOCountersStat{"xvdq": c1, "nvme9n1": c2})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			hdd, _ := findResult(results, "disk-high-latency", "disk:xvdq")
+			assert.Equal(t, "bottleneck", hdd.Typ)
+			assert.Equal(t, "write-await-ms", hdd.Res.PropertyName)
+			assert.InDelta(t, 50, hdd.Res.PropertyValue, 1)
+			assert.InDelta(t, 0.375, hdd.Score, 0.02)
+			if assert.Equal(t, 1, len(hdd.Related)) {
+				assert.Equal(t, "queue-depth-avg", hdd.Related[0].PropertyName)
+				assert.InDelta(t, 6, hdd.Related[0].PropertyValue, 0.5)
+			}
+
+			nvme, _ := findResult(results, "disk-high-latency", "disk:nvme9n1")
+			assert.Equal(t, "read-await-ms", nvme.Res.PropertyName)
+			assert.InDelta(t, 10, nvme.Res.PropertyValue, 1)
+			assert.InDelta(t, 0.47, nvme.Score, 0.05)
+		},
+	}})
+}
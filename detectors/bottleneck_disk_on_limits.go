@@ -20,7 +20,7 @@ func init() {
 			//TODO add Dropped packets as a catalyser for this analysis?
 
 			//DISK LIMIT ON WRITE BPS
-			score, mean := upperRateBoundaries(&dm.WriteBytes, fromLimit, to, opt, 100000.0, [2]float64{0.8, 0.9})
+			score, mean := upperRateBoundaries(&dm.WriteBytes, fromLimit, to, opt, 100000.0, opt.rangeFor("disk-limit-wbps", opt.DiskLimitsRange))
 
 			r := DetectionResult{
 				Typ:   "bottleneck",
@@ -39,7 +39,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopIOByteRate(false) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.IOCounters.WriteBytes.Rate(opt.IORateLoadDuration)
@@ -50,19 +50,14 @@ func init() {
 					if rate < 10000 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "disk-write-bps",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "disk-write-bps", rate)
 					r.Related = append(r.Related, res)
 				}
 			}
 			issues = append(issues, r)
 
 			//DISK LIMIT ON READ BPS
-			score, mean = upperRateBoundaries(&dm.ReadBytes, fromLimit, to, opt, 100000.0, [2]float64{0.8, 0.9})
+			score, mean = upperRateBoundaries(&dm.ReadBytes, fromLimit, to, opt, 100000.0, opt.rangeFor("disk-limit-rbps", opt.DiskLimitsRange))
 
 			r = DetectionResult{
 				Typ:   "bottleneck",
@@ -81,7 +76,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopIOByteRate(true) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.IOCounters.ReadBytes.Rate(opt.IORateLoadDuration)
@@ -92,19 +87,14 @@ func init() {
 					if rate < 10000 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "disk-read-bps",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "disk-read-bps", rate)
 					r.Related = append(r.Related, res)
 				}
 			}
 			issues = append(issues, r)
 
 			//DISK LIMIT ON WRITE OPS
-			score, mean = upperRateBoundaries(&dm.WriteCount, fromLimit, to, opt, 100000.0, [2]float64{0.8, 0.9})
+			score, mean = upperRateBoundaries(&dm.WriteCount, fromLimit, to, opt, 100000.0, opt.rangeFor("disk-limit-wops", opt.DiskLimitsRange))
 
 			r = DetectionResult{
 				Typ:   "bottleneck",
@@ -123,7 +113,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopIOOpRate(false) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.IOCounters.WriteCount.Rate(opt.IORateLoadDuration)
@@ -134,19 +124,14 @@ func init() {
 					if rate < 10 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "disk-write-ops",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "disk-write-ops", rate)
 					r.Related = append(r.Related, res)
 				}
 			}
 			issues = append(issues, r)
 
 			//DISK LIMIT ON READ OPS
-			score, mean = upperRateBoundaries(&dm.ReadCount, fromLimit, to, opt, 100000.0, [2]float64{0.8, 0.9})
+			score, mean = upperRateBoundaries(&dm.ReadCount, fromLimit, to, opt, 100000.0, opt.rangeFor("disk-limit-rops", opt.DiskLimitsRange))
 
 			r = DetectionResult{
 				Typ:   "bottleneck",
@@ -165,7 +150,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopIOOpRate(true) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.IOCounters.WriteCount.Rate(opt.IORateLoadDuration)
@@ -176,12 +161,7 @@ func init() {
 					if rate < 10 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "disk-read-ops",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "disk-read-ops", rate)
 					r.Related = append(r.Related, res)
 				}
 			}
@@ -189,5 +169,5 @@ func init() {
 		}
 
 		return issues
-	})
+	}, "disk-limit-wbps", "disk-limit-rbps", "disk-limit-wops", "disk-limit-rops")
 }
@@ -0,0 +1,48 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskLimitFlatTopWrites(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//10MB/s constant writes
+		name: "writes",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.DiskIO = append(tl.DiskIO, map[string]disk.IOCountersStat{
+				"sda": {Name: "sda", WriteBytes: uint64(i) * 1000000},
+			})
+			tl.Partitions = append(tl.Partitions, []disk.UsageStat{
+				{Path: "/", Fstype: "ext4", Total: 1000000, Free: 900000, InodesTotal: 1000, InodesFree: 900},
+			})
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{{
+				Pid:        123,
+				Name:       "writer",
+				Cmdline:    "writer",
+				IOCounters: &process.IOCountersStat{WriteBytes: uint64(i) * 1000000},
+			}})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+			opt.IOLimitsSpan = 2 * time.Second
+		},
+		wait: 35,
+		check: func(t *testing.T, results []DetectionResult) {
+			wbps, _ := findResult(results, "disk-limit-wbps", "disk:sda")
+			assert.Greater(t, wbps.Score, 0.5)
+			assert.InDelta(t, 10000000, wbps.Res.PropertyValue, 1000000)
+			if assert.Equal(t, 1, len(wbps.Related)) {
+				assert.Equal(t, "writer[123]", wbps.Related[0].Name)
+			}
+
+			rbps, _ := findResult(results, "disk-limit-rbps", "disk:sda")
+			assert.Equal(t, 0.0, rbps.Score)
+		},
+	}})
+}
@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -32,7 +31,7 @@ func init() {
 			PropertyValue: load,
 		}
 
-		r.Score = criticityScore(load, opt.HighMemPercRange)
+		r.Score = criticityScore(load, opt.rangeFor(r.ID, opt.HighMemPercRange))
 		if r.Score == 0 {
 			return []DetectionResult{r}
 		}
@@ -40,7 +39,7 @@ func init() {
 		//get hungry processes
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopMemUsed() {
-			if len(r.Related) >= 5 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 5) {
 				break
 			}
 			mt, ok := proc.MemoryTotal.Last()
@@ -48,15 +47,10 @@ func init() {
 				logrus.Tracef("Couldn't get memory total for pid %d", proc.Pid)
 				continue
 			}
-			res := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "mem-used-bytes",
-				PropertyValue: mt.Value,
-			}
+			res := processResource(proc, "mem-used-bytes", mt.Value)
 			r.Related = append(r.Related, res)
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "mem-low")
 }
@@ -0,0 +1,95 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		issues := make([]DetectionResult, 0)
+
+		for nname, nm := range ActiveStats.NetStats.NICs {
+			//virtual interfaces are checked by net-limit-sbps and net-limit-rbps
+			if nm.LinkSpeed == 0 {
+				continue
+			}
+
+			sent, ok1 := nm.BytesSent.Rate(opt.IORateLoadDuration)
+			recv, ok2 := nm.BytesRecv.Rate(opt.IORateLoadDuration)
+
+			issues = append(issues, linkSaturation(opt, "net-link-send-saturated", nname, nm, "send", sent, recv, ok1 && ok2))
+			issues = append(issues, linkSaturation(opt, "net-link-recv-saturated", nname, nm, "recv", recv, sent, ok1 && ok2))
+		}
+
+		return issues
+	}, "net-link-send-saturated", "net-link-recv-saturated")
+}
+
+//linkSaturation scores the rate of one direction of a nic as a fraction of its link speed
+//On half duplex links both directions share the link capacity
+func linkSaturation(opt *Options, id string, nname string, nm *stats.NICMetrics, dir string, rate float64, otherRate float64, ok bool) DetectionResult {
+	r := DetectionResult{
+		Typ:  "bottleneck",
+		ID:   id,
+		When: time.Now(),
+	}
+	if !ok {
+		r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+		r.Score = -1
+		return r
+	}
+
+	used := rate
+	if nm.Duplex == "half" {
+		used = rate + otherRate
+	}
+	util := used / float64(nm.LinkSpeed)
+
+	r.Score = criticityScore(util, opt.rangeFor(r.ID, opt.NICUtilizationRange))
+	r.Res = Resource{
+		Typ:           "net",
+		Name:          fmt.Sprintf("nic:%s", nname),
+		PropertyName:  fmt.Sprintf("%s-util-perc", dir),
+		PropertyValue: util,
+	}
+	if r.Score == 0 {
+		return r
+	}
+
+	r.Message = fmt.Sprintf("%s is using %.0f%% of its %.0fMbit/s %s duplex link", nname, util*100, float64(nm.LinkSpeed)*8/1000000, nm.Duplex)
+	r.Related = []Resource{{
+		Typ:           "net",
+		Name:          fmt.Sprintf("nic:%s", nname),
+		PropertyName:  fmt.Sprintf("%s-bps", dir),
+		PropertyValue: rate,
+	}}
+
+	//get hungry processes
+	for _, proc := range ActiveStats.ProcessStats.TopNetByteRate(dir == "recv") {
+		if len(r.Related) > opt.maxRelated(r.ID, 3) {
+			break
+		}
+		pn, ok := proc.NetIOCounters[nname]
+		if !ok {
+			continue
+		}
+		pname := "net-sent-bps"
+		prate, ok := pn.BytesSent.Rate(opt.IORateLoadDuration)
+		if dir == "recv" {
+			pname = "net-recv-bps"
+			prate, ok = pn.BytesRecv.Rate(opt.IORateLoadDuration)
+		}
+		if !ok {
+			continue
+		}
+		if prate < 1000 {
+			break
+		}
+		r.Related = append(r.Related, processResource(proc, pname, prate))
+	}
+	return r
+}
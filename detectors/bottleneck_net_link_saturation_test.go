@@ -0,0 +1,57 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetLinkSaturation(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//eth0 (1Gbit/s full duplex) sending 95% of its capacity
+		//eth2 (100Mbit/s half duplex) sending and receiving 40% each
+		//veth0 has no link speed
+		name: "links",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.NICs = append(tl.NICs, []net.IOCountersStat{
+				{Name: "eth0", BytesSent: uint64(i) * 11875000, BytesRecv: uint64(i) * 1000000},
+				{Name: "eth2", BytesSent: uint64(i) * 500000, BytesRecv: uint64(i) * 500000},
+				{Name: "veth0", BytesSent: uint64(i) * 100000000, BytesRecv: uint64(i) * 100000000},
+			})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+			opt.HostRoot = "testdata/hostroot"
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			s0, _ := findResult(results, "net-link-send-saturated", "nic:eth0")
+			assert.Equal(t, "send-util-perc", s0.Res.PropertyName)
+			assert.InDelta(t, 0.95, s0.Res.PropertyValue, 0.01)
+			assert.InDelta(t, 1.0, s0.Score, 0.05)
+			if assert.Equal(t, 1, len(s0.Related)) {
+				assert.Equal(t, "send-bps", s0.Related[0].PropertyName)
+				assert.InDelta(t, 118750000, s0.Related[0].PropertyValue, 1000000)
+			}
+			r0, _ := findResult(results, "net-link-recv-saturated", "nic:eth0")
+			assert.Equal(t, 0.0, r0.Score)
+
+			//half duplex links share capacity between directions
+			s2, _ := findResult(results, "net-link-send-saturated", "nic:eth2")
+			assert.InDelta(t, 0.8, s2.Res.PropertyValue, 0.01)
+			assert.InDelta(t, 0.4, s2.Score, 0.05)
+			r2, _ := findResult(results, "net-link-recv-saturated", "nic:eth2")
+			assert.InDelta(t, 0.4, r2.Score, 0.05)
+
+			//nics with known speed are not checked by the rate curve heuristic
+			_, ok := findResult(results, "net-limit-sbps", "nic:eth0")
+			assert.False(t, ok)
+			_, ok = findResult(results, "net-link-send-saturated", "nic:veth0")
+			assert.False(t, ok)
+			_, ok = findResult(results, "net-limit-sbps", "nic:veth0")
+			assert.True(t, ok)
+		},
+	}})
+}
@@ -19,101 +19,94 @@ func init() {
 
 			//TODO add Dropped packets as a catalyser for this analysis?
 
-			//NET LIMIT ON SENT BPS
-			r := DetectionResult{
-				Typ:  "bottleneck",
-				ID:   "net-limit-sbps",
-				When: time.Now(),
-			}
-
-			score, mean := upperRateBoundaries(&nm.BytesSent, fromLimit, to, opt, 10000.0, [2]float64{0.8, 0.9})
-			r.Score = score
+			//NICs with known link speed are checked against their capacity by net-link-*-saturated
+			if nm.LinkSpeed == 0 {
+				//NET LIMIT ON SENT BPS
+				r := DetectionResult{
+					Typ:  "bottleneck",
+					ID:   "net-limit-sbps",
+					When: time.Now(),
+				}
 
-			r.Res = Resource{
-				Typ:           "net",
-				Name:          fmt.Sprintf("nic:%s", nname),
-				PropertyName:  "send-bps",
-				PropertyValue: mean,
-			}
+				score, mean := upperRateBoundaries(&nm.BytesSent, fromLimit, to, opt, 10000.0, opt.rangeFor(r.ID, opt.NetLimitsRange))
+				r.Score = score
 
-			if r.Score > 0 {
+				r.Res = Resource{
+					Typ:           "net",
+					Name:          fmt.Sprintf("nic:%s", nname),
+					PropertyName:  "send-bps",
+					PropertyValue: mean,
+				}
 
-				//get hungry processes
-				r.Related = make([]Resource, 0)
-				for _, proc := range ActiveStats.ProcessStats.TopNetByteRate(false) {
-					if len(r.Related) >= 3 {
-						break
+				if r.Score > 0 {
+
+					//get hungry processes
+					r.Related = make([]Resource, 0)
+					for _, proc := range ActiveStats.ProcessStats.TopNetByteRate(false) {
+						if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+							break
+						}
+						rate, ok := proc.NetIOCounters[nname].BytesSent.Rate(opt.IORateLoadDuration)
+						if !ok {
+							logrus.Warnf("Couldn't get process net sent bps for net on limits analysis")
+							continue
+						}
+						if rate < 1000 {
+							break
+						}
+						res := processResource(proc, "net-sent-bps", rate)
+						r.Related = append(r.Related, res)
 					}
-					rate, ok := proc.NetIOCounters[nname].BytesSent.Rate(opt.IORateLoadDuration)
-					if !ok {
-						logrus.Warnf("Couldn't get process net sent bps for net on limits analysis")
-						continue
-					}
-					if rate < 1000 {
-						break
-					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-sent-bps",
-						PropertyValue: rate,
-					}
-					r.Related = append(r.Related, res)
 				}
-			}
-			issues = append(issues, r)
+				issues = append(issues, r)
 
-			//NET LIMIT ON RECV BPS
-			r = DetectionResult{
-				Typ:  "bottleneck",
-				ID:   "net-limit-rbps",
-				When: time.Now(),
-			}
+				//NET LIMIT ON RECV BPS
+				r = DetectionResult{
+					Typ:  "bottleneck",
+					ID:   "net-limit-rbps",
+					When: time.Now(),
+				}
 
-			score, mean = upperRateBoundaries(&nm.BytesRecv, fromLimit, to, opt, 10000.0, [2]float64{0.8, 0.9})
-			r.Score = score
+				score, mean = upperRateBoundaries(&nm.BytesRecv, fromLimit, to, opt, 10000.0, opt.rangeFor(r.ID, opt.NetLimitsRange))
+				r.Score = score
 
-			r.Res = Resource{
-				Typ:           "net",
-				Name:          fmt.Sprintf("nic:%s", nname),
-				PropertyName:  "recv-bps",
-				PropertyValue: mean,
-			}
+				r.Res = Resource{
+					Typ:           "net",
+					Name:          fmt.Sprintf("nic:%s", nname),
+					PropertyName:  "recv-bps",
+					PropertyValue: mean,
+				}
 
-			if r.Score > 0 {
-				//get hungry processes
-				r.Related = make([]Resource, 0)
-				for _, proc := range ActiveStats.ProcessStats.TopNetByteRate(true) {
-					if len(r.Related) >= 3 {
-						break
-					}
-					rate, ok := proc.NetIOCounters[nname].BytesRecv.Rate(opt.IORateLoadDuration)
-					if !ok {
-						logrus.Warnf("Couldn't get process recv bps for net on limits analysis")
-						continue
-					}
-					if rate < 1000 {
-						break
-					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-recv-bps",
-						PropertyValue: rate,
+				if r.Score > 0 {
+					//get hungry processes
+					r.Related = make([]Resource, 0)
+					for _, proc := range ActiveStats.ProcessStats.TopNetByteRate(true) {
+						if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+							break
+						}
+						rate, ok := proc.NetIOCounters[nname].BytesRecv.Rate(opt.IORateLoadDuration)
+						if !ok {
+							logrus.Warnf("Couldn't get process recv bps for net on limits analysis")
+							continue
+						}
+						if rate < 1000 {
+							break
+						}
+						res := processResource(proc, "net-recv-bps", rate)
+						r.Related = append(r.Related, res)
 					}
-					r.Related = append(r.Related, res)
 				}
+				issues = append(issues, r)
 			}
-			issues = append(issues, r)
 
 			//NET LIMIT ON SENT OPS
-			r = DetectionResult{
+			r := DetectionResult{
 				Typ:  "bottleneck",
 				ID:   "net-limit-spps",
 				When: time.Now(),
 			}
 
-			score, mean = upperRateBoundaries(&nm.PacketsSent, fromLimit, to, opt, 20.0, [2]float64{0.8, 0.9})
+			score, mean := upperRateBoundaries(&nm.PacketsSent, fromLimit, to, opt, 20.0, opt.rangeFor(r.ID, opt.NetLimitsRange))
 			r.Score = score
 
 			r.Res = Resource{
@@ -127,7 +120,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopNetPacketRate(false) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.NetIOCounters[nname].PacketsSent.Rate(opt.IORateLoadDuration)
@@ -138,12 +131,7 @@ func init() {
 					if rate < 5 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-sent-pps",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "net-sent-pps", rate)
 					r.Related = append(r.Related, res)
 				}
 			}
@@ -156,7 +144,7 @@ func init() {
 				When: time.Now(),
 			}
 
-			score, mean = upperRateBoundaries(&nm.PacketsRecv, fromLimit, to, opt, 10000.0, [2]float64{0.8, 0.9})
+			score, mean = upperRateBoundaries(&nm.PacketsRecv, fromLimit, to, opt, 10000.0, opt.rangeFor(r.ID, opt.NetLimitsRange))
 			r.Score = score
 
 			r.Res = Resource{
@@ -170,7 +158,7 @@ func init() {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopNetPacketRate(true) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					rate, ok := proc.NetIOCounters[nname].PacketsRecv.Rate(opt.IORateLoadDuration)
@@ -181,12 +169,7 @@ func init() {
 					if rate < 5 {
 						break
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-recv-pps",
-						PropertyValue: rate,
-					}
+					res := processResource(proc, "net-recv-pps", rate)
 					r.Related = append(r.Related, res)
 				}
 
@@ -195,5 +178,5 @@ func init() {
 		}
 
 		return issues
-	})
+	}, "net-limit-sbps", "net-limit-rbps", "net-limit-spps", "net-limit-rpps")
 }
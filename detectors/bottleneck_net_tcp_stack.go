@@ -0,0 +1,152 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "net-tcp-retransmits-high",
+			When: time.Now(),
+		}
+
+		ns := ActiveStats.NetSNMPStats
+		retrans, ok1 := ns.TCPRetransSegs.Rate(opt.IORateLoadDuration)
+		out, ok2 := ns.TCPOutSegs.Rate(opt.IORateLoadDuration)
+		timeouts, ok3 := ns.TCPTimeouts.Rate(opt.IORateLoadDuration)
+		if !ok1 || !ok2 || !ok3 {
+			r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+
+		//retransmits of a few segments are not significant
+		ratio := 0.0
+		if out >= 10 {
+			ratio = retrans / out
+		}
+
+		r.Res = Resource{
+			Typ:           "net",
+			Name:          "tcp",
+			PropertyName:  "retransmits-perc",
+			PropertyValue: ratio,
+		}
+		r.Score = criticityScore(ratio, opt.rangeFor(r.ID, opt.TCPRetransRange))
+		if r.Score > 0 {
+			r.Message = fmt.Sprintf("%.1f%% of the sent tcp segments are retransmissions (%.0f/s). %.1f tcp timeouts/s", ratio*100, retrans, timeouts)
+			r.Related = append([]Resource{{
+				Typ:           "net",
+				Name:          "tcp",
+				PropertyName:  "timeouts-ps",
+				PropertyValue: timeouts,
+			}}, listenerProcesses(opt, r.ID, "tcp:")...)
+		}
+
+		return []DetectionResult{r}
+	}, "net-tcp-retransmits-high")
+
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "net-listen-overflow",
+			When: time.Now(),
+		}
+
+		ns := ActiveStats.NetSNMPStats
+		overflows, ok1 := ns.TCPListenOverflows.Rate(opt.IORateLoadDuration)
+		drops, ok2 := ns.TCPListenDrops.Rate(opt.IORateLoadDuration)
+		if !ok1 || !ok2 {
+			r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+
+		//ListenDrops usually includes ListenOverflows, but not on all kernels
+		if overflows > drops {
+			drops = overflows
+		}
+
+		r.Res = Resource{
+			Typ:           "net",
+			Name:          "tcp",
+			PropertyName:  "listen-drops-ps",
+			PropertyValue: drops,
+		}
+		r.Score = criticityScore(drops, opt.rangeFor(r.ID, opt.ListenDropsRange))
+		if r.Score > 0 {
+			r.Message = fmt.Sprintf("%.1f incoming connections/s dropped because the accept queue of listening sockets is full (%.1f/s overflows)", drops, overflows)
+			r.Related = listenerProcesses(opt, r.ID, "tcp:")
+		}
+
+		return []DetectionResult{r}
+	}, "net-listen-overflow")
+
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "net-udp-buffer-errors",
+			When: time.Now(),
+		}
+
+		ns := ActiveStats.NetSNMPStats
+		rcv, ok1 := ns.UDPRcvbufErrors.Rate(opt.IORateLoadDuration)
+		snd, ok2 := ns.UDPSndbufErrors.Rate(opt.IORateLoadDuration)
+		if !ok1 || !ok2 {
+			r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+
+		r.Res = Resource{
+			Typ:           "net",
+			Name:          "udp",
+			PropertyName:  "buffer-errors-ps",
+			PropertyValue: rcv + snd,
+		}
+		r.Score = criticityScore(rcv+snd, opt.rangeFor(r.ID, opt.UDPBufferErrorsRange))
+		if r.Score > 0 {
+			r.Message = fmt.Sprintf("udp datagrams dropped because socket buffers are full. receive=%.1f/s send=%.1f/s", rcv, snd)
+			r.Related = listenerProcesses(opt, r.ID, "udp:")
+		}
+
+		return []DetectionResult{r}
+	}, "net-udp-buffer-errors")
+}
+
+//listenerProcesses processes with most connections that are listening on sockets of a protocol (tcp: or udp:)
+//Listening sockets are in the "listen" label
+func listenerProcesses(opt *Options, id string, proto string) []Resource {
+	related := make([]Resource, 0)
+	for _, proc := range ActiveStats.ProcessStats.TopNetConnCount() {
+		if len(related) >= opt.maxRelated(id, 3) {
+			break
+		}
+		if time.Now().Sub(proc.LastSeen) > opt.IORateLoadDuration {
+			continue
+		}
+		ls := make([]string, 0)
+		for _, l := range proc.Listens {
+			if strings.HasPrefix(l, proto) {
+				ls = append(ls, l)
+			}
+		}
+		if len(ls) == 0 {
+			continue
+		}
+		res := processResource(proc, "net-conns-count", lastValue(&proc.Connections))
+		if res.Labels == nil {
+			res.Labels = make(map[string]string)
+		}
+		res.Labels["listen"] = strings.Join(ls, ",")
+		related = append(related, res)
+	}
+	return related
+}
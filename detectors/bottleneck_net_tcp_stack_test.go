@@ -0,0 +1,55 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetTCPStack(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//1000 segments/s with 5% retransmits, 2 listen drops/s and no udp errors
+		name: "retransmits and listen drops",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.NetSNMP = append(tl.NetSNMP, stats.NetSNMPSample{
+				TCPOutSegs:         uint64(i) * 100,
+				TCPRetransSegs:     uint64(i) * 5,
+				TCPListenOverflows: uint64(i) / 5,
+				TCPListenDrops:     uint64(i) / 5,
+				UDPInDatagrams:     uint64(i) * 10,
+			})
+			conns := 20
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				{Pid: 123, Name: "nginx", Connections: &conns, Listens: []string{"tcp:443", "tcp:80"}},
+				{Pid: 456, Name: "curl", Connections: &conns, Listens: []string{}},
+				{Pid: 789, Name: "dnsmasq", Connections: &conns, Listens: []string{"udp:53"}},
+			})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			rt, _ := findResult(results, "net-tcp-retransmits-high", "")
+			assert.InDelta(t, 0.05, rt.Res.PropertyValue, 0.005)
+			assert.InDelta(t, 0.375, rt.Score, 0.07)
+			if assert.Equal(t, 2, len(rt.Related)) {
+				assert.Equal(t, "timeouts-ps", rt.Related[0].PropertyName)
+				assert.Equal(t, "nginx[123]", rt.Related[1].Name)
+				assert.Equal(t, "tcp:443,tcp:80", rt.Related[1].Labels["listen"])
+			}
+
+			lo, _ := findResult(results, "net-listen-overflow", "")
+			assert.InDelta(t, 2, lo.Res.PropertyValue, 0.5)
+			assert.Equal(t, 1.0, lo.Score)
+			if assert.Equal(t, 1, len(lo.Related)) {
+				assert.Equal(t, "nginx[123]", lo.Related[0].Name)
+			}
+
+			ub, _ := findResult(results, "net-udp-buffer-errors", "")
+			assert.Equal(t, 0.0, ub.Score)
+			assert.Equal(t, 0.0, ub.Res.PropertyValue)
+		},
+	}})
+}
@@ -0,0 +1,137 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := pressureResult(opt, "cpu-pressure", "cpu", ActiveStats.PressureStats.CPU, opt.CPULoadAvgDuration, opt.CPUPressureRange, [2]float64{})
+		if r.Score > 0 {
+			//get cpu eaters
+			r.Related = make([]Resource, 0)
+			for _, proc := range ActiveStats.ProcessStats.TopCPULoad() {
+				if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+					break
+				}
+				pu, ok := stats.TimeLoadPerc(&proc.CPUTimes.User, opt.CPULoadAvgDuration)
+				if !ok {
+					logrus.Tracef("Couldn't get cpu load for pid %d", proc.Pid)
+					continue
+				}
+				ps, _ := stats.TimeLoadPerc(&proc.CPUTimes.System, opt.CPULoadAvgDuration)
+				r.Related = append(r.Related, processResource(proc, "cpu-load-perc", pu+ps))
+			}
+		}
+		return []DetectionResult{r}
+	}, "cpu-pressure")
+
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := pressureResult(opt, "mem-pressure", "mem", ActiveStats.PressureStats.Memory, opt.MemAvgDuration, opt.MemPressureRange, opt.MemPressureFullRange)
+		if r.Score > 0 {
+			//get hungry processes
+			to := time.Now()
+			from := to.Add(-opt.MemAvgDuration)
+			r.Related = make([]Resource, 0)
+			for _, proc := range ActiveStats.ProcessStats.TopMemUsed() {
+				if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+					break
+				}
+				pused, ok := proc.MemoryTotal.Avg(from, to)
+				if !ok {
+					logrus.Tracef("Couldn't get used mem for pid %d", proc.Pid)
+					continue
+				}
+				r.Related = append(r.Related, processResource(proc, "mem-used-bytes", pused))
+			}
+		}
+		return []DetectionResult{r}
+	}, "mem-pressure")
+
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := pressureResult(opt, "io-pressure", "disk", ActiveStats.PressureStats.IO, opt.IORateLoadDuration, opt.IOPressureRange, opt.IOPressureFullRange)
+		if r.Score > 0 {
+			//get most waited processes
+			r.Related = make([]Resource, 0)
+			for _, proc := range ActiveStats.ProcessStats.TopCPUIOWait() {
+				if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+					break
+				}
+				iw, ok := stats.TimeLoadPerc(&proc.CPUTimes.IOWait, opt.CPULoadAvgDuration)
+				if !ok {
+					logrus.Tracef("Couldn't get iowait time for pid %d", proc.Pid)
+					continue
+				}
+				r.Related = append(r.Related, processResource(proc, "cpu-iowait-perc", iw))
+			}
+		}
+		return []DetectionResult{r}
+	}, "io-pressure")
+}
+
+//pressureResult scores the share of time in which some tasks (someRange) or all non idle tasks (fullRange)
+//were stalled waiting for a resource during timespan. fullRange is not used if it is [0, 0]
+func pressureResult(opt *Options, id string, resTyp string, pm *stats.PressureMetrics, timespan time.Duration, someRange [2]float64, fullRange [2]float64) DetectionResult {
+	r := DetectionResult{
+		Typ:  "bottleneck",
+		ID:   id,
+		When: time.Now(),
+		Res: Resource{
+			Typ:          resTyp,
+			Name:         "pressure:" + resTyp,
+			PropertyName: "some-stall-perc",
+		},
+	}
+
+	if !ActiveStats.PressureStats.Supported {
+		r.Message = "Pressure stall information not supported. It requires Linux 4.20+ with PSI enabled"
+		r.Score = -1
+		return r
+	}
+
+	to := time.Now()
+	from := to.Add(-timespan)
+	//avg10 values averaged over the analysis timespan
+	some := stats.TimeValuesRange(&pm.SomeAvg10, from, to)
+	if len(some) == 0 {
+		r.Message = notEnoughDataMessage(timespan)
+		r.Score = -1
+		return r
+	}
+	r.Res.PropertyValue = timeValuesAvg(some)
+	r.Score = criticityScore(r.Res.PropertyValue, opt.rangeFor(id, someRange))
+
+	if fullRange != [2]float64{} {
+		full := timeValuesAvg(stats.TimeValuesRange(&pm.FullAvg10, from, to))
+		fullScore := criticityScore(full, fullRange)
+		if fullScore > r.Score {
+			r.Res.PropertyName = "full-stall-perc"
+			r.Res.PropertyValue = full
+			r.Score = fullScore
+		}
+	}
+
+	//longer term stall for reference
+	avg60, ok := pm.SomeAvg60.Last()
+	if ok && r.Score > 0 {
+		full60, _ := pm.FullAvg60.Last()
+		r.Message = fmt.Sprintf("Tasks stalled waiting for %s. Last 60s: some=%.0f%% full=%.0f%%", resTyp, avg60.Value*100, full60.Value*100)
+	}
+	return r
+}
+
+func timeValuesAvg(tvs []signalutils.TimeValue) float64 {
+	if len(tvs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, tv := range tvs {
+		sum = sum + tv.Value
+	}
+	return sum / float64(len(tvs))
+}
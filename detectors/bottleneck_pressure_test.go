@@ -0,0 +1,56 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPressure(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//memory stalls with all tasks stalled 30% of the time
+		name: "memory stalls",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Pressure = append(tl.Pressure, stats.PressureSample{
+				Supported: true,
+				CPU:       stats.PressureStat{Some: stats.PressureLine{Avg10: 10, Avg60: 10}},
+				Memory: stats.PressureStat{
+					Some: stats.PressureLine{Avg10: 35, Avg60: 30},
+					Full: stats.PressureLine{Avg10: 30, Avg60: 25},
+				},
+			})
+		},
+		options: func(opt *Options) {
+			opt.CPULoadAvgDuration = 1 * time.Second
+			opt.MemAvgDuration = 1 * time.Second
+			opt.IORateLoadDuration = 1 * time.Second
+		},
+		wait: 15,
+		check: func(t *testing.T, results []DetectionResult) {
+			mp, _ := findResult(results, "mem-pressure", "")
+			assert.Equal(t, 1.0, mp.Score)
+			assert.Equal(t, "full-stall-perc", mp.Res.PropertyName)
+			assert.InDelta(t, 0.3, mp.Res.PropertyValue, 0.001)
+			assert.Contains(t, mp.Message, "some=30% full=25%")
+
+			cp, _ := findResult(results, "cpu-pressure", "")
+			assert.Equal(t, 0.0, cp.Score)
+			assert.Equal(t, "some-stall-perc", cp.Res.PropertyName)
+			assert.InDelta(t, 0.1, cp.Res.PropertyValue, 0.001)
+
+			io, _ := findResult(results, "io-pressure", "")
+			assert.Equal(t, 0.0, io.Score)
+			assert.Equal(t, "pressure:disk", io.Res.Name)
+		},
+	}})
+}
+
+func TestPressureNotSupported(t *testing.T) {
+	opt := NewOptions()
+	ActiveStats = &StatsType{PressureStats: &stats.PressureStats{}}
+	r := pressureResult(&opt, "io-pressure", "disk", ActiveStats.PressureStats.IO, opt.IORateLoadDuration, opt.IOPressureRange, opt.IOPressureFullRange)
+	assert.Equal(t, -1.0, r.Score)
+	assert.Contains(t, r.Message, "not supported")
+}
@@ -0,0 +1,84 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := DetectionResult{
+			Typ:  "bottleneck",
+			ID:   "process-stuck-uninterruptible",
+			When: time.Now(),
+		}
+
+		stuck := make([]*stats.ProcessMetrics, 0)
+		for _, proc := range ActiveStats.ProcessStats.Processes {
+			//process is gone
+			if time.Now().Sub(proc.LastSeen) > processSamplePeriod(opt) {
+				continue
+			}
+			if proc.State == stats.ProcessStateUninterruptible && proc.StateDuration() >= opt.ProcessStuckDuration {
+				stuck = append(stuck, proc)
+			}
+		}
+		sort.Slice(stuck, func(i, j int) bool {
+			return stuck[i].StateDuration() > stuck[j].StateDuration()
+		})
+
+		r.Res = Resource{
+			Typ:           "process",
+			Name:          "process:all",
+			PropertyName:  "stuck-uninterruptible-count",
+			PropertyValue: float64(len(stuck)),
+		}
+		r.Score = criticityScore(float64(len(stuck)), opt.rangeFor(r.ID, opt.StuckProcessesRange))
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		//processes blocked on io usually wait for the same mount (ex.: an unresponsive nfs server)
+		mounts := make(map[string]int)
+		related := make([]Resource, 0)
+		for _, proc := range stuck {
+			res := processResource(proc, "uninterruptible-seconds", proc.StateDuration().Seconds())
+			if res.Labels == nil {
+				res.Labels = make(map[string]string)
+			}
+			if proc.Wchan != "" {
+				res.Labels["wchan"] = proc.Wchan
+			}
+			mount := stats.MountOf(proc.Cwd)
+			if mount != "" {
+				res.Labels["mount"] = mount
+				mounts[mount]++
+			}
+			related = append(related, res)
+		}
+
+		r.Message = fmt.Sprintf("%d processes are blocked in uninterruptible sleep (D state) for more than %s. They usually wait for a slow or unresponsive disk or network filesystem", len(stuck), opt.ProcessStuckDuration)
+		r.Related = make([]Resource, 0)
+		for mount, count := range mounts {
+			r.Related = append(r.Related, Resource{
+				Typ:           "disk",
+				Name:          fmt.Sprintf("partition:%s", mount),
+				PropertyName:  "stuck-processes-count",
+				PropertyValue: float64(count),
+			})
+		}
+		sort.Slice(r.Related, func(i, j int) bool {
+			return r.Related[i].PropertyValue > r.Related[j].PropertyValue
+		})
+		for _, res := range related {
+			if len(r.Related) >= opt.maxRelated(r.ID, 5) {
+				break
+			}
+			r.Related = append(r.Related, res)
+		}
+		return []DetectionResult{r}
+	}, "process-stuck-uninterruptible")
+}
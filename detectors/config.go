@@ -0,0 +1,178 @@
+package detectors
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//LoadOptions loads options from a YAML config file. Keys not present in the file keep their default values
+func LoadOptions(path string) (Options, error) {
+	return LoadOptionsFrom(path, NewOptions())
+}
+
+//LoadOptionsFrom loads options from a YAML config file on top of base options
+func LoadOptionsFrom(path string, base Options) (Options, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return base, err
+	}
+
+	opt := base
+	opt.ScoreGates = make(map[string]ScoreGate)
+	for k, v := range base.ScoreGates {
+		opt.ScoreGates[k] = v
+	}
+	opt.Detectors = make(map[string]DetectorOptions)
+	for k, v := range base.Detectors {
+		opt.Detectors[k] = v
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(&opt)
+	if err != nil {
+		return base, fmt.Errorf("Invalid config file %s. err=%s", path, err)
+	}
+
+	err = opt.Validate()
+	if err != nil {
+		return base, fmt.Errorf("Invalid config file %s. err=%s", path, err)
+	}
+	return opt, nil
+}
+
+//Validate checks if option values are consistent. Errors are reported using the config file key names
+func (o *Options) Validate() error {
+	errs := make([]string, 0)
+
+	ranges := map[string][2]float64{
+		"highCPUPercRange":         o.HighCPUPercRange,
+		"highCPUWaitPercRange":     o.HighCPUWaitPercRange,
+		"runQueueRange":            o.RunQueueRange,
+		"highCPUStealPercRange":    o.HighCPUStealPercRange,
+		"highMemPercRange":         o.HighMemPercRange,
+		"lowDiskPercRange":         o.LowDiskPercRange,
+		"lowFileHandlesPercRange":  o.LowFileHandlesPercRange,
+		"fdUsedRange":              o.FDUsedRange,
+		"processFDLimitRange":      o.ProcessFDLimitRange,
+		"processNprocLimitRange":   o.ProcessNprocLimitRange,
+		"stuckProcessesRange":      o.StuckProcessesRange,
+		"zombiesRange":             o.ZombiesRange,
+		"processRestartsRange":     o.ProcessRestartsRange,
+		"pidExhaustionRange":       o.PidExhaustionRange,
+		"nicErrorsRange":           o.NICErrorsRange,
+		"highSwapBpsRange":         o.HighSwapBpsRange,
+		"highDiskUtilPercRange":    o.HighDiskUtilPercRange,
+		"diskLimitsRange":          o.DiskLimitsRange,
+		"netLimitsRange":           o.NetLimitsRange,
+		"nicUtilizationRange":      o.NICUtilizationRange,
+		"memLeakBytesPerHourRange": o.MemLeakBytesPerHourRange,
+		"containerThrottledRange":  o.ContainerThrottledRange,
+		"containerMemLimitRange":   o.ContainerMemLimitRange,
+		"containerOOMKillsRange":   o.ContainerOOMKillsRange,
+		"oomKillsRange":            o.OOMKillsRange,
+		"diskFullETARange":         o.DiskFullETARange,
+		"diskLatencyHDDRange":      o.DiskLatencyHDDRange,
+		"diskLatencySSDRange":      o.DiskLatencySSDRange,
+		"diskLatencyNVMeRange":     o.DiskLatencyNVMeRange,
+		"tcpRetransRange":          o.TCPRetransRange,
+		"listenDropsRange":         o.ListenDropsRange,
+		"udpBufferErrorsRange":     o.UDPBufferErrorsRange,
+		"ephemeralPortsRange":      o.EphemeralPortsRange,
+		"cpuPressureRange":         o.CPUPressureRange,
+		"memPressureRange":         o.MemPressureRange,
+		"memPressureFullRange":     o.MemPressureFullRange,
+		"ioPressureRange":          o.IOPressureRange,
+		"ioPressureFullRange":      o.IOPressureFullRange,
+	}
+	for k, r := range ranges {
+		errs = appendRangeError(errs, k, r)
+	}
+
+	durations := map[string]time.Duration{
+		"timeseriesSize":       o.DefaultTimeseriesSize,
+		"cpuLoadAvgDuration":   o.CPULoadAvgDuration,
+		"ioRateLoadDuration":   o.IORateLoadDuration,
+		"memAvgDuration":       o.MemAvgDuration,
+		"memLeakDuration":      o.MemLeakDuration,
+		"ioLimitsSpan":         o.IOLimitsSpan,
+		"containerOOMKillSpan": o.ContainerOOMKillSpan,
+		"oomKillSpan":          o.OOMKillSpan,
+		"diskFullForecastSpan": o.DiskFullForecastSpan,
+		"processStuckDuration": o.ProcessStuckDuration,
+		"processRestartSpan":   o.ProcessRestartSpan,
+	}
+	for k, d := range durations {
+		if d <= 0 {
+			errs = append(errs, fmt.Sprintf("%s: must be greater than zero", k))
+		}
+	}
+
+	if o.DefaultSampleFreq <= 0 {
+		errs = append(errs, "sampleFreq: must be greater than zero")
+	}
+
+	errs = appendGateErrors(errs, "defaultScoreGate", o.DefaultScoreGate)
+	for id, g := range o.ScoreGates {
+		k := fmt.Sprintf("scoreGates.%s", id)
+		if !knownDetectorID(id) {
+			errs = append(errs, fmt.Sprintf("%s: unknown detector id", k))
+		}
+		errs = appendGateErrors(errs, k, g)
+	}
+
+	for id, d := range o.Detectors {
+		k := fmt.Sprintf("detectors.%s", id)
+		if !knownDetectorID(id) {
+			errs = append(errs, fmt.Sprintf("%s: unknown detector id", k))
+		}
+		if d.Range != [2]float64{} {
+			errs = appendRangeError(errs, fmt.Sprintf("%s.range", k), d.Range)
+		}
+		if d.MaxRelated < 0 {
+			errs = append(errs, fmt.Sprintf("%s.maxRelated: must not be negative", k))
+		}
+	}
+
+	for i, w := range o.Webhooks {
+		errs = appendWebhookErrors(errs, fmt.Sprintf("webhooks.%d", i), w)
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func appendRangeError(errs []string, key string, r [2]float64) []string {
+	if r[0] >= r[1] {
+		return append(errs, fmt.Sprintf("%s: lower bound %v must be less than upper bound %v", key, r[0], r[1]))
+	}
+	return errs
+}
+
+func appendGateErrors(errs []string, key string, g ScoreGate) []string {
+	if g.ClearScore > g.RaiseScore {
+		errs = append(errs, fmt.Sprintf("%s.clearScore: must not be greater than raiseScore", key))
+	}
+	if g.MinDuration < 0 {
+		errs = append(errs, fmt.Sprintf("%s.minDuration: must not be negative", key))
+	}
+	return errs
+}
+
+func knownDetectorID(id string) bool {
+	for _, d := range DetectorIDs {
+		if d == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOptions(t *testing.T) {
+	opt, err := LoadOptions("testdata/config-db.yml")
+	assert.Nil(t, err)
+
+	//defaults
+	assert.Equal(t, [2]float64{0.70, 0.95}, opt.HighCPUPercRange)
+	assert.Equal(t, 1*time.Minute, opt.CPULoadAvgDuration)
+
+	//overrides
+	assert.Equal(t, 30*time.Second, opt.IORateLoadDuration)
+	assert.Equal(t, [2]float64{0.85, 0.98}, opt.HighMemPercRange)
+	assert.Equal(t, 10*time.Second, opt.DefaultScoreGate.MinDuration)
+	assert.InDelta(t, 0.3, opt.ScoreGates["cpu-low-idle"].RaiseScore, 0.001)
+
+	assert.Equal(t, [2]float64{0.7, 0.95}, opt.rangeFor("disk-limit-wbps", opt.DiskLimitsRange))
+	assert.Equal(t, [2]float64{0.8, 0.9}, opt.rangeFor("disk-limit-rbps", opt.DiskLimitsRange))
+	assert.Equal(t, 5, opt.maxRelated("disk-limit-wbps", 3))
+	assert.Equal(t, 3, opt.maxRelated("disk-limit-rbps", 3))
+	assert.False(t, opt.DetectorEnabled("mem-leak"))
+	assert.True(t, opt.DetectorEnabled("mem-low"))
+
+	assert.Equal(t, 2, len(opt.Webhooks))
+	assert.Equal(t, []string{"disk", "mem"}, opt.Webhooks[0].Groups)
+	tmpl, err := opt.Webhooks[0].ParseTemplate()
+	assert.Nil(t, err)
+	assert.NotNil(t, tmpl)
+	wh := opt.Webhooks[0].WithDefaults()
+	assert.Equal(t, 0, *wh.MaxPerMinute)
+	assert.Equal(t, 0, *wh.MaxRetries)
+	wh = opt.Webhooks[1].WithDefaults()
+	assert.Equal(t, "slack", wh.Format)
+	assert.Equal(t, 0.5, *wh.MinScore)
+	assert.Equal(t, 5*time.Minute, wh.MinInterval)
+	assert.Equal(t, 20, *wh.MaxPerMinute)
+	assert.Equal(t, 3, *wh.MaxRetries)
+}
+
+func TestLoadOptionsInvalid(t *testing.T) {
+	_, err := LoadOptions("testdata/config-invalid.yml")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "highMemPercRange: lower bound")
+	assert.Contains(t, err.Error(), "detectors.cpu-low-ilde: unknown detector id")
+	assert.Contains(t, err.Error(), "detectors.disk-low-space.range: lower bound")
+	assert.Contains(t, err.Error(), "webhooks.0.url: must be an http or https URL")
+	assert.Contains(t, err.Error(), "webhooks.0.format: must be json or slack")
+	assert.Contains(t, err.Error(), "webhooks.0.groups: unknown group gpu")
+	assert.Contains(t, err.Error(), "webhooks.0.template:")
+	assert.Contains(t, err.Error(), "webhooks.0: maxPerMinute and maxRetries must not be negative")
+	assert.NotContains(t, err.Error(), "function \"json\" not defined")
+
+	_, err = LoadOptions("testdata/config-unknown-key.yml")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "highMemPercRang")
+
+	_, err = LoadOptions("testdata/not-found.yml")
+	assert.NotNil(t, err)
+}
+
+func TestLoadOptionsExample(t *testing.T) {
+	_, err := LoadOptions("../res/config-example.yml")
+	assert.Nil(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/flaviostutz/perfstat/stats"
@@ -18,66 +20,271 @@ var (
 )
 
 type StatsType struct {
-	CPUStats     *stats.CPUStats
-	ProcessStats *stats.ProcessStats
-	MemStats     *stats.MemStats
-	DiskStats    *stats.DiskStats
-	NetStats     *stats.NetStats
+	CPUStats      *stats.CPUStats
+	ProcessStats  *stats.ProcessStats
+	MemStats      *stats.MemStats
+	DiskStats     *stats.DiskStats
+	NetStats      *stats.NetStats
+	CgroupStats   *stats.CgroupStats
+	PressureStats *stats.PressureStats
+	NetSNMPStats  *stats.NetSNMPStats
+	SocketStats   *stats.SocketStats
+	LoadStats     *stats.LoadStats
+	TaskStats     *stats.TaskStats
 }
 
 //NewOptions create a new default options
 func NewOptions() Options {
 	return Options{
-		HighCPUPercRange:        [2]float64{0.70, 0.95},
-		HighCPUWaitPercRange:    [2]float64{0.05, 0.50},
-		HighMemPercRange:        [2]float64{0.70, 0.95},
-		HighSwapBpsRange:        [2]float64{10000000, 100000000},
-		LowDiskPercRange:        [2]float64{0.70, 0.90},
-		HighDiskUtilPercRange:   [2]float64{0.50, 0.90},
-		LowFileHandlesPercRange: [2]float64{0.70, 0.90},
-		FDUsedRange:             [2]float64{0.6, 0.9},
-		NICErrorsRange:          [2]float64{1, 10},
-		DefaultSampleFreq:       1.0,
-		DefaultTimeseriesSize:   11 * time.Minute,
-		CPULoadAvgDuration:      1 * time.Minute,
-		IORateLoadDuration:      1 * time.Minute,
-		IOLimitsSpan:            1 * time.Minute,
-		MemAvgDuration:          1 * time.Minute,
-		MemLeakDuration:         10 * time.Minute,
+		HighCPUPercRange:         [2]float64{0.70, 0.95},
+		HighCPUWaitPercRange:     [2]float64{0.05, 0.50},
+		RunQueueRange:            [2]float64{1, 3},
+		HighCPUStealPercRange:    [2]float64{0.05, 0.20},
+		HighMemPercRange:         [2]float64{0.70, 0.95},
+		HighSwapBpsRange:         [2]float64{10000000, 100000000},
+		LowDiskPercRange:         [2]float64{0.70, 0.90},
+		HighDiskUtilPercRange:    [2]float64{0.50, 0.90},
+		LowFileHandlesPercRange:  [2]float64{0.70, 0.90},
+		FDUsedRange:              [2]float64{0.6, 0.9},
+		ProcessFDLimitRange:      [2]float64{0.7, 0.9},
+		ProcessNprocLimitRange:   [2]float64{0.7, 0.9},
+		StuckProcessesRange:      [2]float64{0, 5},
+		ZombiesRange:             [2]float64{20, 200},
+		ProcessRestartsRange:     [2]float64{2, 10},
+		PidExhaustionRange:       [2]float64{0.7, 0.9},
+		NICErrorsRange:           [2]float64{1, 10},
+		DiskLimitsRange:          [2]float64{0.8, 0.9},
+		NetLimitsRange:           [2]float64{0.8, 0.9},
+		NICUtilizationRange:      [2]float64{0.7, 0.95},
+		MemLeakBytesPerHourRange: [2]float64{10000000, 500000000},
+		ContainerThrottledRange:  [2]float64{0.1, 0.5},
+		ContainerMemLimitRange:   [2]float64{0.8, 0.95},
+		ContainerOOMKillsRange:   [2]float64{0, 1},
+		OOMKillsRange:            [2]float64{0, 1},
+		DiskFullETARange:         [2]float64{3600, 86400},
+		DiskLatencyHDDRange:      [2]float64{20, 100},
+		DiskLatencySSDRange:      [2]float64{5, 50},
+		DiskLatencyNVMeRange:     [2]float64{1, 20},
+		TCPRetransRange:          [2]float64{0.02, 0.10},
+		ListenDropsRange:         [2]float64{0, 1},
+		UDPBufferErrorsRange:     [2]float64{0, 10},
+		EphemeralPortsRange:      [2]float64{0.5, 0.9},
+		CPUPressureRange:         [2]float64{0.20, 0.60},
+		MemPressureRange:         [2]float64{0.10, 0.40},
+		MemPressureFullRange:     [2]float64{0.05, 0.20},
+		IOPressureRange:          [2]float64{0.20, 0.60},
+		IOPressureFullRange:      [2]float64{0.10, 0.40},
+		DefaultSampleFreq:        1.0,
+		DefaultTimeseriesSize:    11 * time.Minute,
+		CPULoadAvgDuration:       1 * time.Minute,
+		IORateLoadDuration:       1 * time.Minute,
+		IOLimitsSpan:             1 * time.Minute,
+		MemAvgDuration:           1 * time.Minute,
+		MemLeakDuration:          10 * time.Minute,
+		ContainerOOMKillSpan:     10 * time.Minute,
+		OOMKillSpan:              10 * time.Minute,
+		DiskFullForecastSpan:     10 * time.Minute,
+		ProcessStuckDuration:     30 * time.Second,
+		ProcessRestartSpan:       10 * time.Minute,
+		ScoreGates:               make(map[string]ScoreGate),
+		Detectors:                make(map[string]DetectorOptions),
 	}
 }
 
 //Options performance analysis options
 type Options struct {
-	Loglevel                string
-	HighCPUPercRange        [2]float64
-	HighCPUWaitPercRange    [2]float64
-	HighMemPercRange        [2]float64
-	LowDiskPercRange        [2]float64
-	LowFileHandlesPercRange [2]float64
-	FDUsedRange             [2]float64
-	NICErrorsRange          [2]float64
-	HighSwapBpsRange        [2]float64
-	HighDiskUtilPercRange   [2]float64
-	DefaultSampleFreq       float64
-	DefaultTimeseriesSize   time.Duration
-	CPULoadAvgDuration      time.Duration
-	IORateLoadDuration      time.Duration
-	MemAvgDuration          time.Duration
-	MemLeakDuration         time.Duration
-	IOLimitsSpan            time.Duration
+	Loglevel                 string        `yaml:"logLevel"`
+	HighCPUPercRange         [2]float64    `yaml:"highCPUPercRange"`
+	HighCPUWaitPercRange     [2]float64    `yaml:"highCPUWaitPercRange"`
+	RunQueueRange            [2]float64    `yaml:"runQueueRange"`
+	HighCPUStealPercRange    [2]float64    `yaml:"highCPUStealPercRange"`
+	HighMemPercRange         [2]float64    `yaml:"highMemPercRange"`
+	LowDiskPercRange         [2]float64    `yaml:"lowDiskPercRange"`
+	LowFileHandlesPercRange  [2]float64    `yaml:"lowFileHandlesPercRange"`
+	FDUsedRange              [2]float64    `yaml:"fdUsedRange"`
+	ProcessFDLimitRange      [2]float64    `yaml:"processFDLimitRange"`
+	ProcessNprocLimitRange   [2]float64    `yaml:"processNprocLimitRange"`
+	StuckProcessesRange      [2]float64    `yaml:"stuckProcessesRange"`
+	ZombiesRange             [2]float64    `yaml:"zombiesRange"`
+	ProcessRestartsRange     [2]float64    `yaml:"processRestartsRange"`
+	PidExhaustionRange       [2]float64    `yaml:"pidExhaustionRange"`
+	NICErrorsRange           [2]float64    `yaml:"nicErrorsRange"`
+	HighSwapBpsRange         [2]float64    `yaml:"highSwapBpsRange"`
+	HighDiskUtilPercRange    [2]float64    `yaml:"highDiskUtilPercRange"`
+	DiskLimitsRange          [2]float64    `yaml:"diskLimitsRange"`
+	NetLimitsRange           [2]float64    `yaml:"netLimitsRange"`
+	NICUtilizationRange      [2]float64    `yaml:"nicUtilizationRange"`
+	MemLeakBytesPerHourRange [2]float64    `yaml:"memLeakBytesPerHourRange"`
+	ContainerThrottledRange  [2]float64    `yaml:"containerThrottledRange"`
+	ContainerMemLimitRange   [2]float64    `yaml:"containerMemLimitRange"`
+	ContainerOOMKillsRange   [2]float64    `yaml:"containerOOMKillsRange"`
+	OOMKillsRange            [2]float64    `yaml:"oomKillsRange"`
+	DiskFullETARange         [2]float64    `yaml:"diskFullETARange"`
+	DiskLatencyHDDRange      [2]float64    `yaml:"diskLatencyHDDRange"`
+	DiskLatencySSDRange      [2]float64    `yaml:"diskLatencySSDRange"`
+	DiskLatencyNVMeRange     [2]float64    `yaml:"diskLatencyNVMeRange"`
+	TCPRetransRange          [2]float64    `yaml:"tcpRetransRange"`
+	ListenDropsRange         [2]float64    `yaml:"listenDropsRange"`
+	UDPBufferErrorsRange     [2]float64    `yaml:"udpBufferErrorsRange"`
+	EphemeralPortsRange      [2]float64    `yaml:"ephemeralPortsRange"`
+	CPUPressureRange         [2]float64    `yaml:"cpuPressureRange"`
+	MemPressureRange         [2]float64    `yaml:"memPressureRange"`
+	MemPressureFullRange     [2]float64    `yaml:"memPressureFullRange"`
+	IOPressureRange          [2]float64    `yaml:"ioPressureRange"`
+	IOPressureFullRange      [2]float64    `yaml:"ioPressureFullRange"`
+	DefaultSampleFreq        float64       `yaml:"sampleFreq"`
+	DefaultTimeseriesSize    time.Duration `yaml:"timeseriesSize"`
+	CPULoadAvgDuration       time.Duration `yaml:"cpuLoadAvgDuration"`
+	IORateLoadDuration       time.Duration `yaml:"ioRateLoadDuration"`
+	MemAvgDuration           time.Duration `yaml:"memAvgDuration"`
+	MemLeakDuration          time.Duration `yaml:"memLeakDuration"`
+	IOLimitsSpan             time.Duration `yaml:"ioLimitsSpan"`
+	ContainerOOMKillSpan     time.Duration `yaml:"containerOOMKillSpan"`
+	OOMKillSpan              time.Duration `yaml:"oomKillSpan"`
+	DiskFullForecastSpan     time.Duration `yaml:"diskFullForecastSpan"`
+	ProcessRestartSpan       time.Duration `yaml:"processRestartSpan"`
+	//ProcessStuckDuration min time in D state for a process to be considered stuck
+	ProcessStuckDuration time.Duration `yaml:"processStuckDuration"`
+	//HostRoot dir where host root is mounted when running inside a container. ex.: /host
+	HostRoot string `yaml:"hostRoot"`
+	//Source where stats are read from. Defaults to the running system when nil
+	Source stats.Source `yaml:"-"`
+	//DefaultScoreGate hysteresis and min duration rules for detectors without an entry in ScoreGates
+	DefaultScoreGate ScoreGate `yaml:"defaultScoreGate"`
+	//ScoreGates hysteresis and min duration rules by detector ID
+	ScoreGates map[string]ScoreGate `yaml:"scoreGates"`
+	//Detectors per detector overrides by detector ID
+	Detectors map[string]DetectorOptions `yaml:"detectors"`
+	//Webhooks destinations notified when issues cross a score threshold
+	Webhooks []WebhookOptions `yaml:"webhooks"`
+}
+
+//DetectorOptions overrides for a specific detector ID
+type DetectorOptions struct {
+	//Disabled results from this detector are not reported
+	Disabled bool `yaml:"disabled"`
+	//Range criticity range used for scoring instead of the detector default
+	Range [2]float64 `yaml:"range"`
+	//MaxRelated max number of related resources reported instead of the detector default
+	MaxRelated int `yaml:"maxRelated"`
 }
 
 //Resource a computational resource
 type Resource struct {
 	//Typ resource type. ex: ram, disk, cpu, fs, net
-	Typ string
+	Typ string `json:"type"`
 	//Name identification of the resource in the system. ex: /dev/fda021, cpu:23, process:prometheus[6456]
-	Name string
+	Name string `json:"name"`
 	//PropertyName name of the resource property. ex: available-cpu, disk-writes-per-second
-	PropertyName string
+	PropertyName string `json:"propertyName"`
 	//PropertyValue value of the resource property
-	PropertyValue float64
+	PropertyValue float64 `json:"propertyValue"`
+	//Labels additional info about the resource. ex: cgroup, container_id, container_name and pod_uid for processes
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+//processResource a process related to an issue, labeled with the container it runs in
+func processResource(proc *stats.ProcessMetrics, propertyName string, propertyValue float64) Resource {
+	return Resource{
+		Typ:           "process",
+		Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
+		PropertyName:  propertyName,
+		PropertyValue: propertyValue,
+		Labels:        containerLabels(proc.Cgroup, proc.ContainerID, proc.ContainerName, proc.PodUID),
+	}
+}
+
+//containerResource a container, labeled with its cgroup and IDs
+func containerResource(cm *stats.CgroupMetrics, propertyName string, propertyValue float64) Resource {
+	return Resource{
+		Typ:           "container",
+		Name:          cm.ContainerName,
+		PropertyName:  propertyName,
+		PropertyValue: propertyValue,
+		Labels:        containerLabels(cm.Path, cm.ContainerID, cm.ContainerName, cm.PodUID),
+	}
+}
+
+//containerLabels labels with the non empty container attribution values. nil if all of them are empty
+func containerLabels(cgroup string, containerID string, containerName string, podUID string) map[string]string {
+	var labels map[string]string
+	values := map[string]string{
+		"cgroup":         cgroup,
+		"container_id":   containerID,
+		"container_name": containerName,
+		"pod_uid":        podUID,
+	}
+	for k, v := range values {
+		if v != "" {
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+//containerProcesses filters processes that run in a container
+func containerProcesses(procs []*stats.ProcessMetrics, containerID string) []*stats.ProcessMetrics {
+	cp := make([]*stats.ProcessMetrics, 0)
+	for _, p := range procs {
+		if p.ContainerID == containerID {
+			cp = append(cp, p)
+		}
+	}
+	return cp
+}
+
+//oomVictims processes that disappeared when the OOM killer killed processes, which were probably killed by it
+//kills are cumulative oom kill counts. Only processes from containerID are considered if it's not empty
+func oomVictims(kills []signalutils.TimeValue, containerID string) []Resource {
+	procs := ActiveStats.ProcessStats.Processes
+	lastStep := time.Time{}
+	for _, p := range procs {
+		if p.LastSeen.After(lastStep) {
+			lastStep = p.LastSeen
+		}
+	}
+
+	victims := make([]Resource, 0)
+	seen := make(map[int32]bool)
+	for i := 1; i < len(kills); i++ {
+		n := int(kills[i].Value - kills[i-1].Value)
+		//processes must have been sampled after the kill for knowing which ones are gone
+		if n <= 0 || !lastStep.After(kills[i].Time) {
+			continue
+		}
+		//victims were last seen between the previous sample and the one that counted the kill
+		from := kills[i-1].Time.Add(-kills[i].Time.Sub(kills[i-1].Time))
+		gone := make([]*stats.ProcessMetrics, 0)
+		for _, p := range procs {
+			if seen[p.Pid] || p.LastSeen.Before(from) || p.LastSeen.After(kills[i].Time) {
+				continue
+			}
+			if containerID != "" && p.ContainerID != containerID {
+				continue
+			}
+			gone = append(gone, p)
+		}
+		//the OOM killer chooses the processes using more memory
+		sort.Slice(gone, func(a, b int) bool {
+			return lastValue(&gone[a].MemoryTotal) > lastValue(&gone[b].MemoryTotal)
+		})
+		for j := 0; j < n && j < len(gone); j++ {
+			seen[gone[j].Pid] = true
+			victims = append(victims, processResource(gone[j], "mem-used-bytes", lastValue(&gone[j].MemoryTotal)))
+		}
+	}
+	return victims
+}
+
+func lastValue(ts *signalutils.Timeseries) float64 {
+	v, ok := ts.Last()
+	if !ok {
+		return 0
+	}
+	return v.Value
 }
 
 func (r *Resource) String() string {
@@ -87,37 +294,118 @@ func (r *Resource) String() string {
 //DetectionResult detection results
 type DetectionResult struct {
 	//Typ type of issue: bottleneck, risk, harm, lib-error
-	Typ string
+	Typ string `json:"type"`
 	//Id name of the issue. ex: low-available-cpu, disk-write-ceil
-	ID string
+	ID string `json:"id"`
 	//Score how critical is this issue to the health of the system
-	Score float64
+	Score float64 `json:"score"`
 	//Message text indicating the issue details
-	Message string
+	Message string `json:"message"`
 	//Res resource directly related to the issue
-	Res Resource
+	Res Resource `json:"resource"`
 	//Related related resources to the issue (ex: for low CPU, place top 3 CPU processes)
-	Related []Resource
+	Related []Resource `json:"related"`
 	//InfoURL contains more information on how to deal with the issue
-	InfoURL string
+	InfoURL string `json:"infoURL"`
 	//When time of detection process
-	When time.Time
+	When time.Time `json:"when"`
 }
 
 func (i *DetectionResult) String() string {
 	return fmt.Sprintf("type=%s id=%s score=%.2f resource=[%s] message=%s infoURL=%s", i.Typ, i.ID, i.Score, i.Res.String(), i.Message, i.InfoURL)
 }
 
+//GroupFromID subsystem of an issue (cpu, mem, disk, net, container, process), taken from its ID prefix
+//Issues about io stalls (io-*) and file descriptors (fd-*) are in the disk group and about pids (pid-*) in the process group
+func GroupFromID(id string) string {
+	idx := strings.Index(id, "-")
+	if idx == -1 {
+		return "ERROR"
+	}
+	g := id[:idx]
+	if g == "io" || g == "fd" {
+		return "disk"
+	}
+	if g == "pid" {
+		return "process"
+	}
+	return g
+}
+
 //DetectorFunc function that is called for detecting issues on the system
 type DetectorFunc func(*Options) []DetectionResult
 
 //DetectorFuncs detector functions
 var DetectorFuncs = make([]DetectorFunc, 0)
 
+//DetectorIDs IDs of the issues reported by the registered detectors
+var DetectorIDs = make([]string, 0)
+
 //RegisterDetector register a new function to be called for detecting issues on the system
-func RegisterDetector(d DetectorFunc) {
-	logrus.Debugf("Registering detector %v", d)
+//ids are the issue IDs this function may report
+func RegisterDetector(d DetectorFunc, ids ...string) {
+	logrus.Debugf("Registering detector %v %v", d, ids)
 	DetectorFuncs = append(DetectorFuncs, d)
+	DetectorIDs = append(DetectorIDs, ids...)
+}
+
+//DetectorEnabled returns false if detector id was disabled in options
+func (o *Options) DetectorEnabled(id string) bool {
+	d, ok := o.Detectors[id]
+	return !ok || !d.Disabled
+}
+
+//rangeFor returns the criticity range configured for detector id or def if not overridden
+func (o *Options) rangeFor(id string, def [2]float64) [2]float64 {
+	d, ok := o.Detectors[id]
+	if ok && d.Range != [2]float64{} {
+		return d.Range
+	}
+	return def
+}
+
+//maxRelated returns the max number of related resources for detector id or def if not overridden
+func (o *Options) maxRelated(id string, def int) int {
+	d, ok := o.Detectors[id]
+	if ok && d.MaxRelated > 0 {
+		return d.MaxRelated
+	}
+	return def
+}
+
+//Accelerated returns a copy of the options for analysing a timeline replayed speed times faster
+//than it was recorded. Time spans are shortened so that they cover the same samples
+func (o Options) Accelerated(speed float64) Options {
+	if speed == 1 || speed <= 0 {
+		return o
+	}
+	scale := func(d time.Duration) time.Duration {
+		return time.Duration(float64(d) / speed)
+	}
+	o.DefaultSampleFreq = o.DefaultSampleFreq * speed
+	o.DefaultTimeseriesSize = scale(o.DefaultTimeseriesSize)
+	o.CPULoadAvgDuration = scale(o.CPULoadAvgDuration)
+	o.IORateLoadDuration = scale(o.IORateLoadDuration)
+	o.IOLimitsSpan = scale(o.IOLimitsSpan)
+	o.MemAvgDuration = scale(o.MemAvgDuration)
+	o.MemLeakDuration = scale(o.MemLeakDuration)
+	o.ContainerOOMKillSpan = scale(o.ContainerOOMKillSpan)
+	o.OOMKillSpan = scale(o.OOMKillSpan)
+	o.DiskFullForecastSpan = scale(o.DiskFullForecastSpan)
+	o.ProcessStuckDuration = scale(o.ProcessStuckDuration)
+	o.ProcessRestartSpan = scale(o.ProcessRestartSpan)
+	//memory grows speed times faster in wall clock time
+	o.MemLeakBytesPerHourRange = [2]float64{o.MemLeakBytesPerHourRange[0] * speed, o.MemLeakBytesPerHourRange[1] * speed}
+	//and disks get full speed times sooner
+	o.DiskFullETARange = [2]float64{o.DiskFullETARange[0] / speed, o.DiskFullETARange[1] / speed}
+	o.DefaultScoreGate.MinDuration = scale(o.DefaultScoreGate.MinDuration)
+	gates := make(map[string]ScoreGate)
+	for id, g := range o.ScoreGates {
+		g.MinDuration = scale(g.MinDuration)
+		gates[id] = g
+	}
+	o.ScoreGates = gates
+	return o
 }
 
 //calculates a score between 0-1. 0 is "no worry"; 1 is "IT BROKE!"
@@ -128,18 +416,36 @@ func criticityScore(value float64, criticityRange [2]float64) float64 {
 	return math.Min((value-criticityRange[0])/(criticityRange[1]-criticityRange[0]), 1.0)
 }
 
+//inverseCriticityScore calculates a score between 0-1 for values that are worse when lower
+//score starts at criticityRange[1] and is max at criticityRange[0]
+func inverseCriticityScore(value float64, criticityRange [2]float64) float64 {
+	if value > criticityRange[1] {
+		return 0
+	}
+	return math.Min((criticityRange[1]-value)/(criticityRange[1]-criticityRange[0]), 1.0)
+}
+
 func SetLogLevel(level logrus.Level) {
 	logrus.SetLevel(level)
 }
 
 func Start(ctx context.Context, opt Options) {
 	if !Started {
+		if opt.HostRoot != "" {
+			stats.SetHostRoot(opt.HostRoot)
+		}
 		ActiveStats = &StatsType{}
-		ActiveStats.CPUStats = stats.NewCPUStats(ctx, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
-		ActiveStats.ProcessStats = stats.NewProcessStats(ctx, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.CPULoadAvgDuration, opt.MemAvgDuration, opt.DefaultSampleFreq)
-		ActiveStats.MemStats = stats.NewMemStats(ctx, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
-		ActiveStats.DiskStats = stats.NewDiskStats(ctx, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.DefaultSampleFreq)
-		ActiveStats.NetStats = stats.NewNetStats(ctx, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.DefaultSampleFreq)
+		ActiveStats.CPUStats = stats.NewCPUStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.ProcessStats = stats.NewProcessStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.CPULoadAvgDuration, opt.MemAvgDuration, opt.ProcessRestartSpan, opt.DefaultSampleFreq)
+		ActiveStats.MemStats = stats.NewMemStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.DiskStats = stats.NewDiskStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.DefaultSampleFreq)
+		ActiveStats.NetStats = stats.NewNetStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.IORateLoadDuration, opt.DefaultSampleFreq)
+		ActiveStats.CgroupStats = stats.NewCgroupStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.PressureStats = stats.NewPressureStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.NetSNMPStats = stats.NewNetSNMPStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.SocketStats = stats.NewSocketStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.LoadStats = stats.NewLoadStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
+		ActiveStats.TaskStats = stats.NewTaskStats(ctx, opt.Source, opt.DefaultTimeseriesSize, opt.DefaultSampleFreq)
 		Opt = opt
 		Started = true
 	}
@@ -1,11 +1,143 @@
 package detectors
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/mem"
 	"github.com/stretchr/testify/assert"
 )
 
+//scriptedCase a scenario replayed by all detectors from a scripted timeline sampled at 10Hz
+type scriptedCase struct {
+	name string
+	//size timeline size. defaults to 100 samples
+	size int
+	//sample adds the samples at position i of the timeline. cpu, mem and fd
+	//samples of an idle host are already set and may be replaced
+	sample func(i int, tl *stats.Timeline)
+	//options changes the default options
+	options func(opt *Options)
+	//wait samples that must be processed before running the detectors. defaults to 25
+	wait  int
+	check func(t *testing.T, results []DetectionResult)
+}
+
+//runScriptedCases runs the detectors for each case after the stats processed its samples
+func runScriptedCases(t *testing.T, cases []scriptedCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			size := c.size
+			if size == 0 {
+				size = 100
+			}
+			wait := c.wait
+			if wait == 0 {
+				wait = 25
+			}
+			tl := stats.Timeline{}
+			for i := 0; i < size; i++ {
+				v := float64(i) * 0.1
+				tl.CPU = append(tl.CPU, stats.CPUSample{
+					Total: cpu.TimesStat{User: v / 2, Idle: v / 2},
+					CPUs:  []cpu.TimesStat{{CPU: "cpu0", User: v / 2, Idle: v / 2}},
+				})
+				tl.Mem = append(tl.Mem, stats.MemSample{
+					Memory: mem.VirtualMemoryStat{Total: 1000000000, Available: 800000000},
+				})
+				tl.FD = append(tl.FD, stats.FDSample{Used: 100, Max: 100000})
+				if c.sample != nil {
+					c.sample(i, &tl)
+				}
+			}
+
+			opt := NewOptions()
+			if c.options != nil {
+				c.options(&opt)
+			}
+			c.check(t, detectScripted(t, opt, tl, wait))
+		})
+	}
+}
+
+//detectScripted starts the stats with a scripted source for tl and runs all
+//detectors after wait samples of each sequence were processed
+func detectScripted(t *testing.T, opt Options, tl stats.Timeline, wait int) []DetectionResult {
+	src := stats.NewScriptedSource(tl)
+	opt.DefaultSampleFreq = 10
+	opt.Source = src
+	if opt.HostRoot != "" {
+		defer stats.SetHostRoot("")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	//force stats to be recreated from the scripted source
+	Started = false
+	Start(ctx, opt)
+
+	//a sample was processed once the next one is requested
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for src.Replayed() <= wait {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %d samples. replayed=%d", wait, src.Replayed())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	results := make([]DetectionResult, 0)
+	for _, df := range DetectorFuncs {
+		results = append(results, df(&opt)...)
+	}
+	return results
+}
+
+//findResult returns the last result of a detector. resName filters results by resource name when set
+func findResult(results []DetectionResult, id string, resName string) (DetectionResult, bool) {
+	found := false
+	r := DetectionResult{}
+	for _, dr := range results {
+		if dr.ID == id && (resName == "" || dr.Res.Name == resName) {
+			r = dr
+			found = true
+		}
+	}
+	return r, found
+}
+
+func processWithLimits(pid int32, name string, uid int32, threads int32, fds int32, nofile uint64, nproc uint64) stats.ProcessSample {
+	return stats.ProcessSample{
+		Pid:        pid,
+		Name:       name,
+		UID:        &uid,
+		NumThreads: &threads,
+		NumFDs:     &fds,
+		Limits:     &stats.ProcessLimits{NOFILESoft: nofile, NOFILEHard: nofile * 4, NPROCSoft: nproc, NPROCHard: nproc},
+	}
+}
+
+func processWithState(pid int32, name string, ppid int32, state string, wchan string, cwd string) stats.ProcessSample {
+	return stats.ProcessSample{
+		Pid:   pid,
+		Name:  name,
+		PPid:  &ppid,
+		State: state,
+		Wchan: wchan,
+		Cwd:   cwd,
+	}
+}
+
+func processWithThreads(pid int32, name string, threads int32) stats.ProcessSample {
+	return stats.ProcessSample{
+		Pid:        pid,
+		Name:       name,
+		NumThreads: &threads,
+	}
+}
+
 func TestCriticityScore(t *testing.T) {
 	v := criticityScore(0.3, [2]float64{0.3, 0.6})
 	assert.InDeltaf(t, float64(0), v, 0.01, "")
@@ -38,3 +170,12 @@ func TestCriticityScore(t *testing.T) {
 	assert.InDeltaf(t, 0.6, v, 0.01, "")
 
 }
+
+func TestGroupFromID(t *testing.T) {
+	assert.Equal(t, "cpu", GroupFromID("cpu-pressure"))
+	assert.Equal(t, "container", GroupFromID("container-mem-near-limit"))
+	assert.Equal(t, "disk", GroupFromID("io-pressure"))
+	assert.Equal(t, "disk", GroupFromID("fd-low"))
+	assert.Equal(t, "process", GroupFromID("pid-exhaustion"))
+	assert.Equal(t, "ERROR", GroupFromID("invalid"))
+}
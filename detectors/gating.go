@@ -0,0 +1,117 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+)
+
+//ScoreGate hysteresis and persistence rules applied to detector scores
+//before they are reported. The zero value reports scores as they are
+type ScoreGate struct {
+	//RaiseScore an issue is only reported after its score gets higher than this value
+	RaiseScore float64 `yaml:"raiseScore"`
+	//ClearScore a reported issue is only cleared after its score drops to this value or lower
+	ClearScore float64 `yaml:"clearScore"`
+	//MinDuration score must stay above RaiseScore for this long before the issue is reported
+	MinDuration time.Duration `yaml:"minDuration"`
+}
+
+//ScoreGater applies the ScoreGates configured in Options to detection results
+//It keeps state between detection rounds, so use one instance for each results stream
+type ScoreGater struct {
+	states map[string]*gateState
+}
+
+type gateState struct {
+	active      bool
+	raisedSince time.Time
+}
+
+//NewScoreGater creates a new gater with no issues raised
+func NewScoreGater() *ScoreGater {
+	return &ScoreGater{
+		states: make(map[string]*gateState),
+	}
+}
+
+func (o *Options) scoreGate(id string) ScoreGate {
+	g, ok := o.ScoreGates[id]
+	if ok {
+		return g
+	}
+	return o.DefaultScoreGate
+}
+
+//Apply returns a copy of results with the score of issues that didn't pass the
+//gate rules set to zero. Results sharing the same gate key in a round
+//update the gate state once, with their highest score
+func (g *ScoreGater) Apply(opt *Options, results []DetectionResult, now time.Time) []DetectionResult {
+	//highest score for each gate key in this round
+	scores := make(map[string]float64)
+	ids := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, r := range results {
+		k := gateKey(r)
+		seen[k] = true
+		//not enough data for evaluation
+		if r.Score < 0 {
+			continue
+		}
+		s, ok := scores[k]
+		if !ok || r.Score > s {
+			scores[k] = r.Score
+			ids[k] = r.ID
+		}
+	}
+
+	for k, score := range scores {
+		st, ok := g.states[k]
+		if !ok {
+			st = &gateState{}
+			g.states[k] = st
+		}
+		st.update(opt.scoreGate(ids[k]), score, now)
+	}
+
+	//forget issues that are not reported anymore
+	for k := range g.states {
+		if !seen[k] {
+			delete(g.states, k)
+		}
+	}
+
+	gated := make([]DetectionResult, 0)
+	for _, r := range results {
+		st, ok := g.states[gateKey(r)]
+		if r.Score >= 0 && (!ok || !st.active) {
+			r.Score = 0
+			r.Related = nil
+		}
+		gated = append(gated, r)
+	}
+	return gated
+}
+
+func gateKey(r DetectionResult) string {
+	return fmt.Sprintf("%s|%s|%s", r.Typ, r.ID, r.Res.Name)
+}
+
+func (st *gateState) update(gate ScoreGate, score float64, now time.Time) {
+	if st.active {
+		if score <= gate.ClearScore {
+			st.active = false
+			st.raisedSince = time.Time{}
+		}
+		return
+	}
+	if score > gate.RaiseScore {
+		if st.raisedSince.IsZero() {
+			st.raisedSince = now
+		}
+		if now.Sub(st.raisedSince) >= gate.MinDuration {
+			st.active = true
+		}
+	} else {
+		st.raisedSince = time.Time{}
+	}
+}
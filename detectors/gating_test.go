@@ -0,0 +1,103 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreGateHysteresis(t *testing.T) {
+	opt := NewOptions()
+	opt.ScoreGates["cpu-low-idle"] = ScoreGate{RaiseScore: 0.3, ClearScore: 0.1}
+	g := NewScoreGater()
+	t0 := time.Now()
+
+	v := gateScore(g, &opt, "cpu-low-idle", 0.2, t0)
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	v = gateScore(g, &opt, "cpu-low-idle", 0.4, t0)
+	assert.InDeltaf(t, 0.4, v, 0.01, "")
+
+	//keeps reporting while above ClearScore
+	v = gateScore(g, &opt, "cpu-low-idle", 0.2, t0)
+	assert.InDeltaf(t, 0.2, v, 0.01, "")
+
+	v = gateScore(g, &opt, "cpu-low-idle", 0.05, t0)
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	v = gateScore(g, &opt, "cpu-low-idle", 0.2, t0)
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	//detectors without gates are reported as is
+	v = gateScore(g, &opt, "mem-low", 0.2, t0)
+	assert.InDeltaf(t, 0.2, v, 0.01, "")
+}
+
+func TestScoreGateMinDuration(t *testing.T) {
+	opt := NewOptions()
+	opt.DefaultScoreGate = ScoreGate{MinDuration: 10 * time.Second}
+	g := NewScoreGater()
+	t0 := time.Now()
+
+	v := gateScore(g, &opt, "disk-limit-wbps", 0.9, t0)
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	v = gateScore(g, &opt, "disk-limit-wbps", 0.9, t0.Add(5*time.Second))
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	//not enough data doesn't reset the timer
+	v = gateScore(g, &opt, "disk-limit-wbps", -1, t0.Add(7*time.Second))
+	assert.InDeltaf(t, -1, v, 0.01, "")
+
+	v = gateScore(g, &opt, "disk-limit-wbps", 0.9, t0.Add(10*time.Second))
+	assert.InDeltaf(t, 0.9, v, 0.01, "")
+
+	v = gateScore(g, &opt, "disk-limit-wbps", 0, t0.Add(11*time.Second))
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+
+	//timer restarts after the score drops
+	v = gateScore(g, &opt, "disk-limit-wbps", 0.9, t0.Add(12*time.Second))
+	assert.InDeltaf(t, 0.0, v, 0.01, "")
+}
+
+func TestScoreGateSameKeyResults(t *testing.T) {
+	opt := NewOptions()
+	opt.ScoreGates["disk-low-space"] = ScoreGate{RaiseScore: 0.3, ClearScore: 0.1, MinDuration: 10 * time.Second}
+	g := NewScoreGater()
+	t0 := time.Now()
+
+	//two results with the same gate key in one round (ex.: different properties of the same resource)
+	round := func(s1 float64, s2 float64, now time.Time) []DetectionResult {
+		return g.Apply(&opt, []DetectionResult{
+			{Typ: "risk", ID: "disk-low-space", Score: s1, Res: Resource{Name: "disk", PropertyName: "inodes-free-perc"}},
+			{Typ: "risk", ID: "disk-low-space", Score: s2, Res: Resource{Name: "disk", PropertyName: "space-free-perc"}},
+		}, now)
+	}
+
+	//the lower score must not reset the timer started by the higher one
+	rs := round(0.9, 0.05, t0)
+	assert.InDeltaf(t, 0.0, rs[0].Score, 0.01, "")
+	assert.InDeltaf(t, 0.0, rs[1].Score, 0.01, "")
+
+	rs = round(0.05, 0.9, t0.Add(5*time.Second))
+	assert.InDeltaf(t, 0.0, rs[1].Score, 0.01, "")
+
+	rs = round(0.9, 0.05, t0.Add(10*time.Second))
+	assert.InDeltaf(t, 0.9, rs[0].Score, 0.01, "")
+	assert.InDeltaf(t, 0.05, rs[1].Score, 0.01, "")
+
+	//the issue is only cleared when all results drop
+	rs = round(0.05, 0.2, t0.Add(11*time.Second))
+	assert.InDeltaf(t, 0.05, rs[0].Score, 0.01, "")
+	assert.InDeltaf(t, 0.2, rs[1].Score, 0.01, "")
+
+	rs = round(0.05, 0.05, t0.Add(12*time.Second))
+	assert.InDeltaf(t, 0.0, rs[0].Score, 0.01, "")
+	assert.InDeltaf(t, 0.0, rs[1].Score, 0.01, "")
+}
+
+func gateScore(g *ScoreGater, opt *Options, id string, score float64, now time.Time) float64 {
+	r := DetectionResult{Typ: "bottleneck", ID: id, Score: score, Res: Resource{Name: "res"}}
+	return g.Apply(opt, []DetectionResult{r}, now)[0].Score
+}
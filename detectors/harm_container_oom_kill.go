@@ -0,0 +1,54 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		to := time.Now()
+		from := to.Add(-opt.ContainerOOMKillSpan)
+
+		issues := make([]DetectionResult, 0)
+
+		for _, cm := range ActiveStats.CgroupStats.Cgroups {
+			//containers that are gone are still checked, as they may have been stopped by the oom kill
+			if cm.ContainerID == "" {
+				continue
+			}
+			r := DetectionResult{
+				Typ:  "harm",
+				ID:   "container-oom-kill",
+				When: time.Now(),
+			}
+
+			//oom kills since the start of the span
+			tvs := stats.TimeValuesRange(&cm.MemoryOOMKill, from, to)
+			if len(tvs) == 0 {
+				r.Res = containerResource(cm, "oom-kills-count", 0)
+				r.Message = notEnoughDataMessage(opt.ContainerOOMKillSpan)
+				r.Score = -1
+				issues = append(issues, r)
+				continue
+			}
+			kills := tvs[len(tvs)-1].Value - tvs[0].Value
+
+			r.Res = containerResource(cm, "oom-kills-count", kills)
+			r.Score = criticityScore(kills, opt.rangeFor(r.ID, opt.ContainerOOMKillsRange))
+			if r.Score > 0 {
+				r.Message = fmt.Sprintf("%.0f processes killed for reaching the container memory limit in the last %s", kills, opt.ContainerOOMKillSpan)
+				r.Related = oomVictims(tvs, cm.ContainerID)
+				if cm.MemoryMax > 0 {
+					r.Related = append(r.Related, containerResource(cm, "mem-limit-bytes", float64(cm.MemoryMax)))
+				}
+			}
+			issues = append(issues, r)
+		}
+
+		return issues
+	}, "container-oom-kill")
+}
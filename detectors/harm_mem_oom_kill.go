@@ -0,0 +1,55 @@
+package detectors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "harm",
+			ID:   "mem-oom-kill",
+			When: time.Now(),
+		}
+
+		to := time.Now()
+		from := to.Add(-opt.OOMKillSpan)
+
+		//oom kills since the start of the span
+		tvs := stats.TimeValuesRange(&ActiveStats.MemStats.OOMKill, from, to)
+		if len(tvs) == 0 {
+			r.Message = notEnoughDataMessage(opt.OOMKillSpan)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+		kills := tvs[len(tvs)-1].Value - tvs[0].Value
+
+		r.Res = Resource{
+			Typ:           "mem",
+			Name:          "ram",
+			PropertyName:  "oom-kills-count",
+			PropertyValue: kills,
+		}
+		r.Score = criticityScore(kills, opt.rangeFor(r.ID, opt.OOMKillsRange))
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		r.Message = fmt.Sprintf("%.0f processes killed by the kernel OOM killer in the last %s", kills, opt.OOMKillSpan)
+		r.Related = oomVictims(tvs, "")
+		if len(r.Related) > 0 {
+			names := make([]string, 0)
+			for _, v := range r.Related {
+				names = append(names, v.Name)
+			}
+			r.Message = fmt.Sprintf("%s. Probable victims: %s", r.Message, strings.Join(names, ", "))
+		}
+
+		return []DetectionResult{r}
+	}, "mem-oom-kill")
+}
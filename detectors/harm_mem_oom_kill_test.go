@@ -0,0 +1,50 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOOMKill(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//java is killed by the OOM killer after 1s
+		name: "java killed",
+		size: 40,
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Mem[i] = stats.MemSample{
+				Memory:  mem.VirtualMemoryStat{Total: 1000000000, Available: 100000000},
+				OOMKill: 3,
+			}
+			procs := []stats.ProcessSample{
+				{Pid: 456, Name: "nginx", MemoryInfo: &process.MemoryInfoStat{RSS: 10000000}},
+				{Pid: 789, Name: "sshd", MemoryInfo: &process.MemoryInfoStat{RSS: 5000000}},
+			}
+			if i < 10 {
+				procs = append(procs, stats.ProcessSample{Pid: 123, Name: "java", MemoryInfo: &process.MemoryInfoStat{RSS: 800000000}})
+			} else {
+				tl.Mem[i].OOMKill = 4
+			}
+			tl.Processes = append(tl.Processes, procs)
+		},
+		options: func(opt *Options) {
+			opt.CPULoadAvgDuration = 1 * time.Second
+			opt.MemAvgDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			r, _ := findResult(results, "mem-oom-kill", "")
+			assert.Equal(t, "harm", r.Typ)
+			assert.Equal(t, 1.0, r.Res.PropertyValue)
+			assert.Equal(t, 1.0, r.Score)
+			if assert.Equal(t, 1, len(r.Related)) {
+				assert.Equal(t, "java[123]", r.Related[0].Name)
+				assert.Equal(t, 800000000.0, r.Related[0].PropertyValue)
+			}
+			assert.Contains(t, r.Message, "java[123]")
+		},
+	}})
+}
@@ -0,0 +1,60 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "harm",
+			ID:   "process-crash-loop",
+			When: time.Now(),
+		}
+		rng := opt.rangeFor(r.ID, opt.ProcessRestartsRange)
+
+		from := time.Now().Add(-opt.ProcessRestartSpan)
+		commands := ActiveStats.ProcessStats.TopRestarts(from)
+		if len(commands) == 0 {
+			r.Message = "No process restarts"
+			return []DetectionResult{r}
+		}
+
+		top := commands[0]
+		restarts := float64(top.RestartCount(from))
+		r.Res = commandResource(top, restarts)
+		r.Score = criticityScore(restarts, rng)
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		r.Message = fmt.Sprintf("%s was restarted %.0f times in the last %s. Last exit at %s", top.Name, restarts, opt.ProcessRestartSpan, top.LastExit.Format(time.RFC3339))
+		r.Related = make([]Resource, 0)
+		for _, c := range commands[1:] {
+			cr := float64(c.RestartCount(from))
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) || cr <= rng[0] {
+				break
+			}
+			r.Related = append(r.Related, commandResource(c, cr))
+		}
+		return []DetectionResult{r}
+	}, "process-crash-loop")
+}
+
+//commandResource processes of a command line. Pids change on restarts, so the command name is used
+func commandResource(c *stats.CommandRestarts, restarts float64) Resource {
+	return Resource{
+		Typ:           "process",
+		Name:          c.Name,
+		PropertyName:  "restarts-count",
+		PropertyValue: restarts,
+		Labels: map[string]string{
+			"cmdline":   c.Cmdline,
+			"last_exit": c.LastExit.Format(time.RFC3339),
+		},
+	}
+}
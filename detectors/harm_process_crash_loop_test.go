@@ -0,0 +1,39 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessCrashLoop(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//api is restarted by its supervisor every 3 samples and worker every 6 samples
+		name: "supervised restarts",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				{Pid: 1, Name: "supervisord", Cmdline: "/usr/bin/supervisord"},
+				{Pid: int32(1000 + i/3), Name: "api", Cmdline: "/opt/api/bin/api --config /etc/api.yml"},
+				{Pid: int32(2000 + i/6), Name: "worker", Cmdline: "/opt/api/bin/worker"},
+			})
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			cl, _ := findResult(results, "process-crash-loop", "")
+			assert.Equal(t, "harm", cl.Typ)
+			assert.Equal(t, "api", cl.Res.Name)
+			assert.Equal(t, "restarts-count", cl.Res.PropertyName)
+			assert.InDelta(t, 7, cl.Res.PropertyValue, 1.5)
+			assert.Greater(t, cl.Score, 0.5)
+			assert.Equal(t, "/opt/api/bin/api --config /etc/api.yml", cl.Res.Labels["cmdline"])
+			lastExit, err := time.Parse(time.RFC3339, cl.Res.Labels["last_exit"])
+			assert.Nil(t, err)
+			assert.WithinDuration(t, time.Now(), lastExit, 2*time.Second)
+			if assert.Equal(t, 1, len(cl.Related)) {
+				assert.Equal(t, "worker", cl.Related[0].Name)
+				assert.InDelta(t, 3, cl.Related[0].PropertyValue, 1)
+			}
+		},
+	}})
+}
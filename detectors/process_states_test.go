@@ -0,0 +1,70 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessStates(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//rsync and tar blocked on an nfs mount. worker only got into D state recently
+		//app doesn't reap its children. some zombies have a parent that is not sampled
+		name: "stuck and zombies",
+		sample: func(i int, tl *stats.Timeline) {
+			workerState := "R"
+			if i >= 20 {
+				workerState = "D"
+			}
+			ps := []stats.ProcessSample{
+				processWithState(1, "init", 0, "S", "", ""),
+				processWithState(50, "app", 1, "S", "", ""),
+				processWithState(100, "rsync", 1, "D", "nfs_wait_bit_killable", "/mnt/backup/daily"),
+				processWithState(101, "tar", 1, "D", "", "/mnt/backup"),
+				processWithState(102, "worker", 1, workerState, "", "/"),
+			}
+			for z := 0; z < 25; z++ {
+				ps = append(ps, processWithState(int32(1000+z), "child", 50, "Z", "", ""))
+			}
+			for z := 0; z < 5; z++ {
+				ps = append(ps, processWithState(int32(2000+z), "orphan", 999, "Z", "", ""))
+			}
+			tl.Processes = append(tl.Processes, ps)
+		},
+		options: func(opt *Options) {
+			opt.ProcessStuckDuration = 1 * time.Second
+			opt.HostRoot = "testdata/hostroot"
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			st, _ := findResult(results, "process-stuck-uninterruptible", "")
+			assert.Equal(t, "bottleneck", st.Typ)
+			assert.Equal(t, 2.0, st.Res.PropertyValue)
+			assert.InDelta(t, 0.4, st.Score, 0.01)
+			if assert.Equal(t, 3, len(st.Related)) {
+				assert.Equal(t, "partition:/mnt/backup", st.Related[0].Name)
+				assert.Equal(t, 2.0, st.Related[0].PropertyValue)
+				names := []string{st.Related[1].Name, st.Related[2].Name}
+				assert.Contains(t, names, "rsync[100]")
+				assert.Contains(t, names, "tar[101]")
+				for _, res := range st.Related[1:] {
+					assert.Equal(t, "/mnt/backup", res.Labels["mount"])
+					if res.Name == "rsync[100]" {
+						assert.Equal(t, "nfs_wait_bit_killable", res.Labels["wchan"])
+					}
+				}
+			}
+
+			zb, _ := findResult(results, "process-zombies-high", "")
+			assert.Equal(t, "risk", zb.Typ)
+			assert.Equal(t, 30.0, zb.Res.PropertyValue)
+			assert.InDelta(t, 10.0/180, zb.Score, 0.01)
+			if assert.Equal(t, 2, len(zb.Related)) {
+				assert.Equal(t, "app[50]", zb.Related[0].Name)
+				assert.Equal(t, 25.0, zb.Related[0].PropertyValue)
+				assert.Equal(t, "pid:999", zb.Related[1].Name)
+			}
+		},
+	}})
+}
@@ -0,0 +1,61 @@
+package detectors
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		to := time.Now()
+		from := to.Add(-opt.MemAvgDuration)
+
+		issues := make([]DetectionResult, 0)
+
+		for _, cm := range ActiveStats.CgroupStats.Cgroups {
+			//containers without memory limit are covered by host memory detectors. Skip containers that are gone
+			if cm.ContainerID == "" || cm.MemoryMax == 0 || cm.LastSeen.Before(from) {
+				continue
+			}
+			r := DetectionResult{
+				Typ:  "risk",
+				ID:   "container-mem-near-limit",
+				When: time.Now(),
+			}
+
+			used, ok := cm.MemoryWorkingSet.Avg(from, to)
+			if !ok {
+				r.Res = containerResource(cm, "mem-limit-perc", 0)
+				r.Message = notEnoughDataMessage(opt.MemAvgDuration)
+				r.Score = -1
+				issues = append(issues, r)
+				continue
+			}
+			usedPerc := used / float64(cm.MemoryMax)
+
+			r.Res = containerResource(cm, "mem-limit-perc", usedPerc)
+			r.Score = criticityScore(usedPerc, opt.rangeFor(r.ID, opt.ContainerMemLimitRange))
+
+			if r.Score > 0 {
+				//get container memory eaters
+				r.Related = make([]Resource, 0)
+				for _, proc := range containerProcesses(ActiveStats.ProcessStats.TopMemUsed(), cm.ContainerID) {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+						break
+					}
+					pused, ok := proc.MemoryTotal.Avg(from, to)
+					if !ok {
+						logrus.Tracef("Couldn't get used mem for pid %d", proc.Pid)
+						continue
+					}
+					r.Related = append(r.Related, processResource(proc, "mem-used-bytes", pused))
+				}
+			}
+			issues = append(issues, r)
+		}
+
+		return issues
+	}, "container-mem-near-limit")
+}
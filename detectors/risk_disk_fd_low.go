@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -28,7 +27,7 @@ func init() {
 		maxFD := ActiveStats.DiskStats.FD.MaxFD
 		fdUsedPerc := float64(usedFD) / float64(maxFD)
 
-		r.Score = criticityScore(fdUsedPerc, opt.FDUsedRange)
+		r.Score = criticityScore(fdUsedPerc, opt.rangeFor(r.ID, opt.FDUsedRange))
 		r.Res = Resource{
 			Typ:           "fd",
 			Name:          "fd",
@@ -43,7 +42,7 @@ func init() {
 		//get hungry processes
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopFD() {
-			if len(r.Related) >= 3 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 				break
 			}
 			pused, ok := proc.FD.Avg(from, to)
@@ -51,15 +50,10 @@ func init() {
 				logrus.Tracef("Couldn't get used fd for pid %d", proc.Pid)
 				continue
 			}
-			res := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "disk-fd-used-count",
-				PropertyValue: pused,
-			}
+			res := processResource(proc, "disk-fd-used-count", pused)
 			r.Related = append(r.Related, res)
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "fd-low")
 }
@@ -0,0 +1,97 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		issues := make([]DetectionResult, 0)
+
+		for pname, part := range ActiveStats.DiskStats.Partitions {
+			issues = append(issues, diskFullForecast(opt, "disk-space-full-eta", pname, "space", &part.Free))
+			if part.InodesTotal > 0 {
+				issues = append(issues, diskFullForecast(opt, "disk-inodes-full-eta", pname, "inodes", &part.InodesFree))
+			}
+		}
+
+		return issues
+	}, "disk-space-full-eta", "disk-inodes-full-eta")
+}
+
+//diskFullForecast estimates when free will get to zero by linear regression of its recent values
+//PropertyValue is the number of seconds until it is full or -1 if it's not being filled up
+func diskFullForecast(opt *Options, id string, pname string, what string, free *signalutils.Timeseries) DetectionResult {
+	r := DetectionResult{
+		Typ:  "risk",
+		ID:   id,
+		When: time.Now(),
+		Res: Resource{
+			Typ:           "disk",
+			Name:          fmt.Sprintf("partition:%s", pname),
+			PropertyName:  fmt.Sprintf("%s-full-eta-seconds", what),
+			PropertyValue: -1,
+		},
+	}
+
+	to := time.Now()
+	from := to.Add(-opt.DiskFullForecastSpan)
+
+	_, ok := free.Get(from)
+	if !ok {
+		r.Message = notEnoughDataMessage(opt.DiskFullForecastSpan)
+		r.Score = -1
+		return r
+	}
+	last, ok := free.Last()
+	if !ok {
+		r.Message = notEnoughDataMessage(opt.DiskFullForecastSpan)
+		r.Score = -1
+		return r
+	}
+
+	_, beta, r0 := free.LinearRegression(from, to)
+	//linear regression error is too high
+	if r0 < 0.4 {
+		r.Message = "Analysis is inconclusive"
+		return r
+	}
+
+	decrPerSecond := -beta * float64(time.Second.Nanoseconds())
+	if decrPerSecond <= 0 {
+		return r
+	}
+
+	eta := last.Value / decrPerSecond
+	r.Res.PropertyValue = eta
+	r.Score = inverseCriticityScore(eta, opt.rangeFor(r.ID, opt.DiskFullETARange))
+	if r.Score == 0 {
+		return r
+	}
+
+	r.Message = fmt.Sprintf("Free %s will be exhausted in %s at the current rate", what, time.Duration(eta*float64(time.Second)).Round(time.Second))
+
+	//get top writing processes
+	r.Related = make([]Resource, 0)
+	for _, proc := range ActiveStats.ProcessStats.TopIOByteRate(false) {
+		if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+			break
+		}
+		rate, ok := proc.IOCounters.WriteBytes.Rate(opt.IORateLoadDuration)
+		if !ok {
+			logrus.Tracef("Couldn't get process write bps for disk full forecast")
+			continue
+		}
+		if rate < 10000 {
+			break
+		}
+		r.Related = append(r.Related, processResource(proc, "disk-write-bps", rate))
+	}
+
+	return r
+}
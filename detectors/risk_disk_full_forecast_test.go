@@ -0,0 +1,56 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskFullForecast(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//1GB/s written to a partition with 100GB free. inodes are stable
+		name: "space",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Partitions = append(tl.Partitions, []disk.UsageStat{
+				{Path: "/", Fstype: "ext4", Total: 200000000000, Free: 100000000000 - uint64(i)*100000000, InodesTotal: 1000, InodesFree: 900},
+			})
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{{
+				Pid:        123,
+				Name:       "writer",
+				Cmdline:    "writer",
+				IOCounters: &process.IOCountersStat{WriteBytes: uint64(i) * 100000000},
+			}})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+			opt.DiskFullForecastSpan = 2 * time.Second
+		},
+		wait: 35,
+		check: func(t *testing.T, results []DetectionResult) {
+			sp, _ := findResult(results, "disk-space-full-eta", "partition:/")
+			assert.Equal(t, "risk", sp.Typ)
+			assert.Equal(t, "space-full-eta-seconds", sp.Res.PropertyName)
+			//~96GB left at 1GB/s
+			assert.InDelta(t, 96, sp.Res.PropertyValue, 5)
+			assert.Equal(t, 1.0, sp.Score)
+			if assert.Equal(t, 1, len(sp.Related)) {
+				assert.Equal(t, "writer[123]", sp.Related[0].Name)
+				assert.InDelta(t, 1000000000, sp.Related[0].PropertyValue, 100000000)
+			}
+
+			in, _ := findResult(results, "disk-inodes-full-eta", "partition:/")
+			assert.Equal(t, -1.0, in.Res.PropertyValue)
+			assert.Equal(t, 0.0, in.Score)
+		},
+	}})
+}
+
+func TestInverseCriticityScore(t *testing.T) {
+	assert.Equal(t, 0.0, inverseCriticityScore(100, [2]float64{10, 50}))
+	assert.Equal(t, 0.5, inverseCriticityScore(30, [2]float64{10, 50}))
+	assert.Equal(t, 1.0, inverseCriticityScore(5, [2]float64{10, 50}))
+}
@@ -48,7 +48,7 @@ func init() {
 				PropertyValue: usedPerc,
 			}
 
-			r.Score = criticityScore(usedPerc, opt.LowDiskPercRange)
+			r.Score = criticityScore(usedPerc, opt.rangeFor(r.ID, opt.LowDiskPercRange))
 			issues = append(issues, r)
 
 			//PARTITION USED INODES
@@ -81,7 +81,7 @@ func init() {
 
 			usedPerc = float64(total) / max
 
-			r.Score = criticityScore(usedPerc, opt.LowDiskPercRange)
+			r.Score = criticityScore(usedPerc, opt.rangeFor(r.ID, opt.LowDiskPercRange))
 			r.Res = Resource{
 				Typ:           "disk",
 				Name:          fmt.Sprintf("partition:%s", pname),
@@ -93,5 +93,5 @@ func init() {
 		}
 
 		return issues
-	})
+	}, "disk-low-space", "disk-low-inodes")
 }
@@ -40,13 +40,14 @@ func init() {
 				r.Score = -1
 				return []DetectionResult{r}
 			}
-			ranges := [2]float64{opt.HighDiskUtilPercRange[0] * float64(cc), opt.HighDiskUtilPercRange[1] * float64(cc)}
+			utilRange := opt.rangeFor(r.ID, opt.HighDiskUtilPercRange)
+			ranges := [2]float64{utilRange[0] * float64(cc), utilRange[1] * float64(cc)}
 			r.Score = criticityScore(utilPerc, ranges)
 
 			//get processes waiting for IOs
 			r.Related = make([]Resource, 0)
 			for _, proc := range ActiveStats.ProcessStats.TopCPUIOWait() {
-				if len(r.Related) >= 3 {
+				if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 					break
 				}
 				iw, ok := stats.TimeLoadPerc(&proc.CPUTimes.IOWait, opt.CPULoadAvgDuration)
@@ -54,16 +55,11 @@ func init() {
 					logrus.Tracef("Couldn't get iowait time for pid %d", proc.Pid)
 					continue
 				}
-				res := Resource{
-					Typ:           "process",
-					Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-					PropertyName:  "cpu-iowait-perc",
-					PropertyValue: iw,
-				}
+				res := processResource(proc, "cpu-iowait-perc", iw)
 				r.Related = append(r.Related, res)
 			}
 			issues = append(issues, r)
 		}
 		return issues
-	})
+	}, "disk-high-util")
 }
@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -42,8 +41,9 @@ func init() {
 			PropertyValue: incrPerHour,
 		}
 
-		//memory leak is important if growing more than 10MB per hour
-		r.Score = criticityScore(incrPerHour, [2]float64{10000000, 500000000})
+		//memory leak is important if growing more than 10MB per hour (by default)
+		leakRange := opt.rangeFor(r.ID, opt.MemLeakBytesPerHourRange)
+		r.Score = criticityScore(incrPerHour, leakRange)
 
 		if r.Score == 0 {
 			return []DetectionResult{r}
@@ -53,7 +53,7 @@ func init() {
 		r.Related = make([]Resource, 0)
 		evalcount := 0
 		for _, proc := range ActiveStats.ProcessStats.TopMemUsed() {
-			if len(r.Related) >= 3 || evalcount > 10 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) || evalcount > 10 {
 				break
 			}
 
@@ -72,19 +72,14 @@ func init() {
 			}
 
 			pincrPerHour := pbeta * float64((1 * time.Hour).Nanoseconds())
-			if pincrPerHour < 10000000 {
+			if pincrPerHour < leakRange[0] {
 				continue
 			}
 
-			re := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "mem-hourly-bytes",
-				PropertyValue: pincrPerHour,
-			}
+			re := processResource(proc, "mem-hourly-bytes", pincrPerHour)
 			r.Related = append(r.Related, re)
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "mem-leak")
 }
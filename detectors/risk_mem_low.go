@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -44,7 +43,7 @@ func init() {
 			PropertyValue: usedPerc,
 		}
 
-		r.Score = criticityScore(usedPerc, opt.HighMemPercRange)
+		r.Score = criticityScore(usedPerc, opt.rangeFor(r.ID, opt.HighMemPercRange))
 
 		if r.Score == 0 {
 			return []DetectionResult{r}
@@ -53,7 +52,7 @@ func init() {
 		//get hungry processes
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopMemUsed() {
-			if len(r.Related) >= 3 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 				break
 			}
 			pused, ok := proc.MemoryTotal.Avg(from, to)
@@ -61,12 +60,7 @@ func init() {
 				logrus.Tracef("Couldn't get used mem for pid %d", proc.Pid)
 				continue
 			}
-			res := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "mem-used-bytes",
-				PropertyValue: pused,
-			}
+			res := processResource(proc, "mem-used-bytes", pused)
 			r.Related = append(r.Related, res)
 		}
 
@@ -95,5 +89,5 @@ func init() {
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "mem-low")
 }
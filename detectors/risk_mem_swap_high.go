@@ -1,7 +1,6 @@
 package detectors
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -37,7 +36,7 @@ func init() {
 			PropertyValue: sin + sout,
 		}
 
-		r.Score = criticityScore(sin+sout, opt.HighSwapBpsRange)
+		r.Score = criticityScore(sin+sout, opt.rangeFor(r.ID, opt.HighSwapBpsRange))
 
 		if r.Score == 0 {
 			return []DetectionResult{r}
@@ -46,7 +45,7 @@ func init() {
 		//get processes with high swap
 		r.Related = make([]Resource, 0)
 		for _, proc := range ActiveStats.ProcessStats.TopMemSwap() {
-			if len(r.Related) >= 5 {
+			if len(r.Related) >= opt.maxRelated(r.ID, 5) {
 				break
 			}
 
@@ -54,15 +53,10 @@ func init() {
 			if !ok {
 				continue
 			}
-			re := Resource{
-				Typ:           "process",
-				Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-				PropertyName:  "mem-swap-bytes",
-				PropertyValue: swap,
-			}
+			re := processResource(proc, "mem-swap-bytes", swap)
 			r.Related = append(r.Related, re)
 		}
 
 		return []DetectionResult{r}
-	})
+	}, "mem-swap-high")
 }
@@ -0,0 +1,63 @@
+package detectors
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+
+		r := DetectionResult{
+			Typ:  "risk",
+			ID:   "net-ephemeral-ports-low",
+			When: time.Now(),
+		}
+
+		to := time.Now()
+		from := to.Add(-opt.IORateLoadDuration)
+
+		ss := ActiveStats.SocketStats
+		used, ok := ss.EphemeralPortsUsed.Avg(from, to)
+		if !ok || ss.EphemeralPortsTotal == 0 {
+			r.Message = notEnoughDataMessage(opt.IORateLoadDuration)
+			r.Score = -1
+			return []DetectionResult{r}
+		}
+		usedPerc := used / float64(ss.EphemeralPortsTotal)
+
+		r.Score = criticityScore(usedPerc, opt.rangeFor(r.ID, opt.EphemeralPortsRange))
+		r.Res = Resource{
+			Typ:           "net",
+			Name:          "tcp",
+			PropertyName:  "ephemeral-ports-used-perc",
+			PropertyValue: usedPerc,
+		}
+
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		timeWait := ss.StateCount("TIME_WAIT")
+		r.Message = fmt.Sprintf("%.0f of %d ephemeral ports used by connections to %s. %.0f sockets in TIME_WAIT", used, ss.EphemeralPortsTotal, ss.EphemeralTopRemote, timeWait)
+
+		//sockets in TIME_WAIT hold ports after connections are closed
+		r.Related = []Resource{{
+			Typ:           "net",
+			Name:          "tcp",
+			PropertyName:  "time-wait-count",
+			PropertyValue: timeWait,
+		}}
+		for _, proc := range ActiveStats.ProcessStats.TopNetConnCount() {
+			if len(r.Related) > opt.maxRelated(r.ID, 3) {
+				break
+			}
+			if time.Now().Sub(proc.LastSeen) > opt.IORateLoadDuration {
+				continue
+			}
+			r.Related = append(r.Related, processResource(proc, "net-conns-count", lastValue(&proc.Connections)))
+		}
+
+		return []DetectionResult{r}
+	}, "net-ephemeral-ports-low")
+}
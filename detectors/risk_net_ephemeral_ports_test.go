@@ -0,0 +1,46 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeralPortsLow(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//21000 of 28232 ephemeral ports used by connections to a database
+		name: "time wait",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Sockets = append(tl.Sockets, stats.SocketSample{
+				States:             map[string]uint64{"ESTABLISHED": 1000, "TIME_WAIT": 20000, "LISTEN": 2},
+				EphemeralPortsMin:  32768,
+				EphemeralPortsMax:  60999,
+				EphemeralPortsUsed: 21000,
+				EphemeralTopRemote: "10.0.0.2:5432",
+			})
+			conns1 := 900
+			conns2 := 100
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				{Pid: 123, Name: "worker", Connections: &conns1},
+				{Pid: 456, Name: "api", Connections: &conns2},
+			})
+		},
+		options: func(opt *Options) {
+			opt.IORateLoadDuration = 1 * time.Second
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			ep, _ := findResult(results, "net-ephemeral-ports-low", "")
+			assert.InDelta(t, 0.744, ep.Res.PropertyValue, 0.001)
+			assert.InDelta(t, 0.61, ep.Score, 0.01)
+			assert.Contains(t, ep.Message, "10.0.0.2:5432")
+			if assert.Equal(t, 3, len(ep.Related)) {
+				assert.Equal(t, "time-wait-count", ep.Related[0].PropertyName)
+				assert.Equal(t, 20000.0, ep.Related[0].PropertyValue)
+				assert.Equal(t, "worker[123]", ep.Related[1].Name)
+				assert.Equal(t, 900.0, ep.Related[1].PropertyValue)
+			}
+		},
+	}})
+}
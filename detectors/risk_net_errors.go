@@ -35,13 +35,13 @@ func init() {
 				PropertyValue: errRate,
 			}
 
-			r.Score = criticityScore(errRate, opt.NICErrorsRange)
+			r.Score = criticityScore(errRate, opt.rangeFor(r.ID, opt.NICErrorsRange))
 
 			if r.Score > 0 {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopNetErrRate(true) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					perr, ok := proc.TotalNetIOCounters.ErrIn.Rate(opt.IORateLoadDuration)
@@ -49,12 +49,7 @@ func init() {
 						logrus.Tracef("Couldn't get net err in for pid %d", proc.Pid)
 						continue
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-errin-pps",
-						PropertyValue: perr,
-					}
+					res := processResource(proc, "net-errin-pps", perr)
 					r.Related = append(r.Related, res)
 				}
 			}
@@ -81,13 +76,13 @@ func init() {
 				PropertyValue: errRate,
 			}
 
-			r.Score = criticityScore(errRate, opt.NICErrorsRange)
+			r.Score = criticityScore(errRate, opt.rangeFor(r.ID, opt.NICErrorsRange))
 
 			if r.Score > 0 {
 				//get hungry processes
 				r.Related = make([]Resource, 0)
 				for _, proc := range ActiveStats.ProcessStats.TopNetErrRate(false) {
-					if len(r.Related) >= 3 {
+					if len(r.Related) >= opt.maxRelated(r.ID, 3) {
 						break
 					}
 					perr, ok := proc.TotalNetIOCounters.ErrOut.Rate(opt.IORateLoadDuration)
@@ -95,12 +90,7 @@ func init() {
 						logrus.Tracef("Couldn't get net err out for pid %d", proc.Pid)
 						continue
 					}
-					res := Resource{
-						Typ:           "process",
-						Name:          fmt.Sprintf("%s[%d]", proc.Name, proc.Pid),
-						PropertyName:  "net-errout-pps",
-						PropertyValue: perr,
-					}
+					res := processResource(proc, "net-errout-pps", perr)
 					r.Related = append(r.Related, res)
 				}
 
@@ -108,5 +98,5 @@ func init() {
 			issues = append(issues, r)
 		}
 		return issues
-	})
+	}, "net-high-errin", "net-high-errout")
 }
@@ -0,0 +1,95 @@
+package detectors
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := DetectionResult{
+			Typ:  "risk",
+			ID:   "pid-exhaustion",
+			When: time.Now(),
+		}
+
+		//every thread uses a pid, so tasks are checked against the kernel and container pid limits
+		//the limit closest to be reached is the issue resource
+		var top *pidLimitUsage
+		check := func(u pidLimitUsage) {
+			if top == nil || u.res.PropertyValue > top.res.PropertyValue {
+				top = &u
+			}
+		}
+
+		ts := ActiveStats.TaskStats
+		tasks := lastValue(&ts.Tasks)
+		if ts.PidMax > 0 {
+			check(pidLimitUsage{res: kernelLimitResource("pid_max", tasks, ts.PidMax), used: tasks, limit: ts.PidMax})
+		}
+		if ts.ThreadsMax > 0 {
+			check(pidLimitUsage{res: kernelLimitResource("threads-max", tasks, ts.ThreadsMax), used: tasks, limit: ts.ThreadsMax})
+		}
+
+		for _, cm := range ActiveStats.CgroupStats.Cgroups {
+			//skip containers that are gone
+			if cm.ContainerID == "" || cm.PidsMax == 0 || time.Now().Sub(cm.LastSeen) > processSamplePeriod(opt) {
+				continue
+			}
+			used := lastValue(&cm.PidsCurrent)
+			res := containerResource(cm, "pids-used-perc", used/float64(cm.PidsMax))
+			if res.Labels == nil {
+				res.Labels = make(map[string]string)
+			}
+			res.Labels["limit"] = strconv.FormatUint(cm.PidsMax, 10)
+			check(pidLimitUsage{res: res, used: used, limit: cm.PidsMax, containerID: cm.ContainerID})
+		}
+
+		if top == nil {
+			r.Message = "No pid limits available"
+			return []DetectionResult{r}
+		}
+		r.Res = top.res
+		r.Score = criticityScore(top.res.PropertyValue, opt.rangeFor(r.ID, opt.PidExhaustionRange))
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		r.Message = fmt.Sprintf("%.0f of %d pids used (%s). New processes and threads will fail to start when the limit is reached", top.used, top.limit, r.Res.Name)
+		procs := ActiveStats.ProcessStats.TopThreads()
+		if top.containerID != "" {
+			procs = containerProcesses(procs, top.containerID)
+		}
+		r.Related = make([]Resource, 0)
+		for _, proc := range procs {
+			if len(r.Related) >= opt.maxRelated(r.ID, 5) {
+				break
+			}
+			//process is gone
+			if time.Now().Sub(proc.LastSeen) > processSamplePeriod(opt) {
+				continue
+			}
+			r.Related = append(r.Related, processResource(proc, "threads-count", lastValue(&proc.NumThreads)))
+		}
+		return []DetectionResult{r}
+	}, "pid-exhaustion")
+}
+
+type pidLimitUsage struct {
+	res         Resource
+	used        float64
+	limit       uint64
+	containerID string
+}
+
+//kernelLimitResource a kernel pid limit with its value in the "limit" label
+func kernelLimitResource(name string, tasks float64, limit uint64) Resource {
+	return Resource{
+		Typ:           "process",
+		Name:          fmt.Sprintf("kernel:%s", name),
+		PropertyName:  "tasks-used-perc",
+		PropertyValue: tasks / float64(limit),
+		Labels:        map[string]string{"limit": strconv.FormatUint(limit, 10)},
+	}
+}
@@ -0,0 +1,42 @@
+package detectors
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPidExhaustion(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//a java thread pool is taking most of the pids allowed by pid_max
+		name: "thread pool",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Tasks = append(tl.Tasks, stats.TaskSample{Tasks: 30000, PidMax: 32768, ThreadsMax: 63432})
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				processWithThreads(1, "init", 1),
+				processWithThreads(100, "java", 28000),
+				processWithThreads(200, "nginx", 50),
+				processWithThreads(300, "postgres", 20),
+			})
+		},
+		options: func(opt *Options) {
+			opt.Detectors["pid-exhaustion"] = DetectorOptions{MaxRelated: 2}
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			pe, _ := findResult(results, "pid-exhaustion", "")
+			assert.Equal(t, "risk", pe.Typ)
+			assert.Equal(t, "kernel:pid_max", pe.Res.Name)
+			assert.Equal(t, "tasks-used-perc", pe.Res.PropertyName)
+			assert.InDelta(t, 30000.0/32768, pe.Res.PropertyValue, 0.001)
+			assert.Equal(t, "32768", pe.Res.Labels["limit"])
+			assert.Equal(t, 1.0, pe.Score)
+			if assert.Equal(t, 2, len(pe.Related)) {
+				assert.Equal(t, "java[100]", pe.Related[0].Name)
+				assert.Equal(t, "threads-count", pe.Related[0].PropertyName)
+				assert.Equal(t, 28000.0, pe.Related[0].PropertyValue)
+				assert.Equal(t, "nginx[200]", pe.Related[1].Name)
+			}
+		},
+	}})
+}
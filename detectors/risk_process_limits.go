@@ -0,0 +1,110 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		return []DetectionResult{processLimitRisk(opt, "process-fd-near-limit", "fd", "open files", opt.ProcessFDLimitRange,
+			func(proc *stats.ProcessMetrics) (float64, uint64, uint64) {
+				return lastValue(&proc.FD), proc.Limits.NOFILESoft, proc.Limits.NOFILEHard
+			})}
+	}, "process-fd-near-limit")
+
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		return []DetectionResult{processLimitRisk(opt, "process-nproc-near-limit", "nproc", "processes/threads", opt.ProcessNprocLimitRange,
+			func(proc *stats.ProcessMetrics) (float64, uint64, uint64) {
+				//the limit is checked against all threads of the process real user
+				//root is not limited
+				if proc.UID <= 0 {
+					return 0, 0, 0
+				}
+				return float64(ActiveStats.ProcessStats.UserThreads[proc.UID]), proc.Limits.NPROCSoft, proc.Limits.NPROCHard
+			})}
+	}, "process-nproc-near-limit")
+}
+
+type processLimitUsage struct {
+	proc  *stats.ProcessMetrics
+	used  float64
+	soft  uint64
+	hard  uint64
+	ratio float64
+}
+
+//processLimitRisk scores the running process which is closest to its own soft limit of a resource
+//Other processes over the range start are related
+func processLimitRisk(opt *Options, id string, name string, what string, rng [2]float64, usage func(*stats.ProcessMetrics) (used float64, soft uint64, hard uint64)) DetectionResult {
+	r := DetectionResult{
+		Typ:  "risk",
+		ID:   id,
+		When: time.Now(),
+	}
+	rng = opt.rangeFor(id, rng)
+
+	usages := make([]processLimitUsage, 0)
+	for _, proc := range ActiveStats.ProcessStats.Processes {
+		//process is gone
+		if time.Now().Sub(proc.LastSeen) > processSamplePeriod(opt) {
+			continue
+		}
+		used, soft, hard := usage(proc)
+		if soft == 0 {
+			continue
+		}
+		usages = append(usages, processLimitUsage{proc: proc, used: used, soft: soft, hard: hard, ratio: used / float64(soft)})
+	}
+	if len(usages) == 0 {
+		r.Message = "No process limits available"
+		return r
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].ratio > usages[j].ratio
+	})
+
+	top := usages[0]
+	r.Score = criticityScore(top.ratio, rng)
+	r.Res = processLimitResource(top, name)
+	if r.Score == 0 {
+		return r
+	}
+
+	r.Message = fmt.Sprintf("%s is using %.0f of its %d %s soft limit (hard limit %s)", r.Res.Name, top.used, top.soft, what, formatLimit(top.hard))
+	r.Related = make([]Resource, 0)
+	for _, u := range usages[1:] {
+		if len(r.Related) >= opt.maxRelated(r.ID, 3) || u.ratio < rng[0] {
+			break
+		}
+		r.Related = append(r.Related, processLimitResource(u, name))
+	}
+	return r
+}
+
+//processLimitResource a process with its limits in the "limit" and "hard_limit" labels
+func processLimitResource(u processLimitUsage, name string) Resource {
+	res := processResource(u.proc, fmt.Sprintf("%s-limit-used-perc", name), u.ratio)
+	if res.Labels == nil {
+		res.Labels = make(map[string]string)
+	}
+	res.Labels["limit"] = strconv.FormatUint(u.soft, 10)
+	res.Labels["hard_limit"] = formatLimit(u.hard)
+	return res
+}
+
+func formatLimit(limit uint64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return strconv.FormatUint(limit, 10)
+}
+
+//processSamplePeriod max time since processes were last seen to be considered running
+func processSamplePeriod(opt *Options) time.Duration {
+	return time.Duration(2 / opt.DefaultSampleFreq * float64(time.Second))
+}
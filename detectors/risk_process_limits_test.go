@@ -0,0 +1,42 @@
+package detectors
+
+import (
+	"testing"
+
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessLimits(t *testing.T) {
+	runScriptedCases(t, []scriptedCase{{
+		//java and nginx near their open files limit
+		//threads of user 1000 near its processes limit. root is not limited
+		name: "fd and nproc",
+		sample: func(i int, tl *stats.Timeline) {
+			tl.Processes = append(tl.Processes, []stats.ProcessSample{
+				processWithLimits(1, "init", 0, 50, 10, 1024, 10),
+				processWithLimits(100, "java", 1000, 3500, 900, 1024, 4096),
+				processWithLimits(200, "nginx", 1000, 200, 800, 1024, 0),
+				processWithLimits(300, "postgres", 1001, 10, 100, 65536, 4096),
+			})
+		},
+		check: func(t *testing.T, results []DetectionResult) {
+			fd, _ := findResult(results, "process-fd-near-limit", "")
+			assert.Equal(t, "java[100]", fd.Res.Name)
+			assert.Equal(t, "fd-limit-used-perc", fd.Res.PropertyName)
+			assert.InDelta(t, 0.879, fd.Res.PropertyValue, 0.001)
+			assert.InDelta(t, 0.89, fd.Score, 0.01)
+			assert.Equal(t, "1024", fd.Res.Labels["limit"])
+			assert.Equal(t, "4096", fd.Res.Labels["hard_limit"])
+			if assert.Equal(t, 1, len(fd.Related)) {
+				assert.Equal(t, "nginx[200]", fd.Related[0].Name)
+			}
+
+			np, _ := findResult(results, "process-nproc-near-limit", "")
+			assert.Equal(t, "java[100]", np.Res.Name)
+			assert.InDelta(t, 3700.0/4096, np.Res.PropertyValue, 0.001)
+			assert.Equal(t, 1.0, np.Score)
+			assert.Equal(t, 0, len(np.Related))
+		},
+	}})
+}
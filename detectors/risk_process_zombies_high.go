@@ -0,0 +1,72 @@
+package detectors
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flaviostutz/perfstat/stats"
+)
+
+func init() {
+	RegisterDetector(func(opt *Options) []DetectionResult {
+		r := DetectionResult{
+			Typ:  "risk",
+			ID:   "process-zombies-high",
+			When: time.Now(),
+		}
+
+		//zombies are reaped by their parents, so they are counted by parent
+		zombies := 0
+		parents := make(map[int32]int)
+		for _, proc := range ActiveStats.ProcessStats.Processes {
+			//process is gone
+			if time.Now().Sub(proc.LastSeen) > processSamplePeriod(opt) {
+				continue
+			}
+			if proc.State == stats.ProcessStateZombie {
+				zombies++
+				parents[proc.PPid]++
+			}
+		}
+
+		r.Res = Resource{
+			Typ:           "process",
+			Name:          "process:all",
+			PropertyName:  "zombie-count",
+			PropertyValue: float64(zombies),
+		}
+		r.Score = criticityScore(float64(zombies), opt.rangeFor(r.ID, opt.ZombiesRange))
+		if r.Score == 0 {
+			return []DetectionResult{r}
+		}
+
+		ppids := make([]int32, 0)
+		for ppid := range parents {
+			ppids = append(ppids, ppid)
+		}
+		sort.Slice(ppids, func(i, j int) bool {
+			return parents[ppids[i]] > parents[ppids[j]]
+		})
+
+		r.Message = fmt.Sprintf("%d zombie processes. Their parents are not reaping exited children, which may exhaust pids", zombies)
+		r.Related = make([]Resource, 0)
+		for _, ppid := range ppids {
+			if len(r.Related) >= opt.maxRelated(r.ID, 3) {
+				break
+			}
+			parent, ok := ActiveStats.ProcessStats.Processes[ppid]
+			if ok {
+				r.Related = append(r.Related, processResource(parent, "zombie-children-count", float64(parents[ppid])))
+				continue
+			}
+			r.Related = append(r.Related, Resource{
+				Typ:           "process",
+				Name:          fmt.Sprintf("pid:%d", ppid),
+				PropertyName:  "zombie-children-count",
+				PropertyValue: float64(parents[ppid]),
+			})
+		}
+		return []DetectionResult{r}
+	}, "process-zombies-high")
+}
@@ -0,0 +1,110 @@
+package detectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"text/template"
+	"time"
+)
+
+//WebhookOptions a destination that is notified when issues cross a score threshold
+type WebhookOptions struct {
+	//URL where notifications are POSTed
+	URL string `yaml:"url"`
+	//Format payload format: json (default) or slack (Slack compatible incoming webhook)
+	Format string `yaml:"format"`
+	//Template Go template used to render the payload instead of the Format default
+	Template string `yaml:"template"`
+	//Headers additional HTTP headers. ex.: Authorization
+	Headers map[string]string `yaml:"headers"`
+	//MinScore issues are notified when their score gets to this value. Defaults to 0.5 when not set
+	MinScore *float64 `yaml:"minScore"`
+	//Groups only notify issues from these groups (cpu, mem, disk, net, container, process). Defaults to all groups
+	Groups []string `yaml:"groups"`
+	//MinInterval an issue is not notified again before this interval since its last notification. Defaults to 5m
+	MinInterval time.Duration `yaml:"minInterval"`
+	//MaxPerMinute max number of notifications sent per minute to this destination. 0 disables the limit. Defaults to 20 when not set
+	MaxPerMinute *int `yaml:"maxPerMinute"`
+	//MaxRetries retries for failed notifications. 0 disables retries. Defaults to 3 when not set
+	MaxRetries *int `yaml:"maxRetries"`
+	//RetryBackoff wait time before the first retry. It is doubled on each retry. Defaults to 1s
+	RetryBackoff time.Duration `yaml:"retryBackoff"`
+	//Timeout for each request. Defaults to 10s
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+//WebhookTemplateFuncs functions available to webhook templates
+var WebhookTemplateFuncs = template.FuncMap{"json": templateJSON}
+
+//ParseTemplate parses the webhook Template with WebhookTemplateFuncs. Returns nil if Template is empty
+func (w WebhookOptions) ParseTemplate() (*template.Template, error) {
+	if w.Template == "" {
+		return nil, nil
+	}
+	return template.New("webhook").Funcs(WebhookTemplateFuncs).Parse(w.Template)
+}
+
+//templateJSON renders values as JSON in webhook templates. ex.: {"text": {{json .Summary}}}
+func templateJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+//WithDefaults returns a copy of the options with defaults set for values that were not set
+func (w WebhookOptions) WithDefaults() WebhookOptions {
+	if w.Format == "" {
+		w.Format = "json"
+	}
+	if w.MinScore == nil {
+		ms := 0.5
+		w.MinScore = &ms
+	}
+	if w.MinInterval == 0 {
+		w.MinInterval = 5 * time.Minute
+	}
+	if w.MaxPerMinute == nil {
+		mpm := 20
+		w.MaxPerMinute = &mpm
+	}
+	if w.MaxRetries == nil {
+		mr := 3
+		w.MaxRetries = &mr
+	}
+	if w.RetryBackoff == 0 {
+		w.RetryBackoff = 1 * time.Second
+	}
+	if w.Timeout == 0 {
+		w.Timeout = 10 * time.Second
+	}
+	return w
+}
+
+func appendWebhookErrors(errs []string, key string, w WebhookOptions) []string {
+	u, err := url.Parse(w.URL)
+	if w.URL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Sprintf("%s.url: must be an http or https URL", key))
+	}
+	if w.Format != "" && w.Format != "json" && w.Format != "slack" {
+		errs = append(errs, fmt.Sprintf("%s.format: must be json or slack", key))
+	}
+	_, err = w.ParseTemplate()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s.template: %s", key, err))
+	}
+	if w.MinScore != nil && (*w.MinScore < 0 || *w.MinScore > 1) {
+		errs = append(errs, fmt.Sprintf("%s.minScore: must be between 0 and 1", key))
+	}
+	for _, g := range w.Groups {
+		if g != "cpu" && g != "mem" && g != "disk" && g != "net" && g != "container" && g != "process" {
+			errs = append(errs, fmt.Sprintf("%s.groups: unknown group %s", key, g))
+		}
+	}
+	if w.MinInterval < 0 || w.RetryBackoff < 0 || w.Timeout < 0 {
+		errs = append(errs, fmt.Sprintf("%s: durations must not be negative", key))
+	}
+	if (w.MaxPerMinute != nil && *w.MaxPerMinute < 0) || (w.MaxRetries != nil && *w.MaxRetries < 0) {
+		errs = append(errs, fmt.Sprintf("%s: maxPerMinute and maxRetries must not be negative", key))
+	}
+	return errs
+}
@@ -25,10 +25,10 @@ require (
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.6.1
 	github.com/tsenart/vegeta/v12 v12.8.3
-	github.com/yaacov/observer v0.0.0-20180522123346-640b591f6eb5
 	go.mongodb.org/mongo-driver v1.4.0 // indirect
 	golang.org/x/sys v0.0.0-20200728102440-3e129f6d46b1 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200605160147-a5ece683394c
 )
 
 // replace github.com/mum4k/termdash => github.com/flaviostutz/termdash v1.2.0
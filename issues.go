@@ -0,0 +1,175 @@
+package perfstat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+)
+
+const (
+	//IssueOpened an issue was detected for the first time
+	IssueOpened = "opened"
+	//IssueEscalated an open issue score has grown
+	IssueEscalated = "escalated"
+	//IssueDeescalated an open issue score has dropped, but it is still active
+	IssueDeescalated = "de-escalated"
+	//IssueResolved an open issue is not being detected anymore
+	IssueResolved = "resolved"
+
+	//minimum score change since last emitted event to generate escalation events
+	issueScoreStep = 0.1
+)
+
+//IssueEvent a transition in the lifecycle of an issue
+type IssueEvent struct {
+	//When time of the transition
+	When time.Time
+	//Issue last detection result for the issue
+	Issue detectors.DetectionResult
+	//Typ transition type: opened, escalated, de-escalated, resolved
+	Typ string
+	//FirstSeen time the issue was opened
+	FirstSeen time.Time
+	//PeakScore highest score seen while the issue was open
+	PeakScore float64
+	//Duration for how long the issue has been open
+	Duration time.Duration
+}
+
+func (e *IssueEvent) String() string {
+	return fmt.Sprintf("event=%s duration=%s peak=%.2f %s", e.Typ, e.Duration.String(), e.PeakScore, e.Issue.String())
+}
+
+type trackedIssue struct {
+	issue     detectors.DetectionResult
+	firstSeen time.Time
+	peakScore float64
+	lastScore float64
+	lastTyp   string
+}
+
+//issueTracker keeps the state of open issues between detection rounds
+type issueTracker struct {
+	issues map[string]*trackedIssue
+}
+
+func newIssueTracker() *issueTracker {
+	return &issueTracker{
+		issues: make(map[string]*trackedIssue),
+	}
+}
+
+func issueKey(r detectors.DetectionResult) string {
+	return fmt.Sprintf("%s|%s|%s", r.Typ, r.ID, r.Res.Name)
+}
+
+//update compares the current detection results with the open issues
+//and returns the transitions that happened since last update
+func (t *issueTracker) update(results []detectors.DetectionResult, now time.Time) []IssueEvent {
+	events := make([]IssueEvent, 0)
+
+	//group by issue key, keeping the highest score
+	current := make(map[string]detectors.DetectionResult)
+	for _, r := range results {
+		k := issueKey(r)
+		c, ok := current[k]
+		if !ok || r.Score > c.Score {
+			current[k] = r
+		}
+	}
+
+	for k, r := range current {
+		//not enough data to evaluate. keep the issue as is
+		if r.Score < 0 {
+			continue
+		}
+
+		ti, ok := t.issues[k]
+		if !ok {
+			if r.Score == 0 {
+				continue
+			}
+			ti = &trackedIssue{
+				issue:     r,
+				firstSeen: now,
+				peakScore: r.Score,
+				lastScore: r.Score,
+			}
+			t.issues[k] = ti
+			events = append(events, ti.event(IssueOpened, now))
+			continue
+		}
+
+		ti.issue = r
+		if r.Score == 0 {
+			delete(t.issues, k)
+			events = append(events, ti.event(IssueResolved, now))
+			continue
+		}
+
+		if r.Score > ti.peakScore {
+			ti.peakScore = r.Score
+		}
+		if r.Score-ti.lastScore >= issueScoreStep {
+			ti.lastScore = r.Score
+			events = append(events, ti.event(IssueEscalated, now))
+		} else if ti.lastScore-r.Score >= issueScoreStep {
+			ti.lastScore = r.Score
+			events = append(events, ti.event(IssueDeescalated, now))
+		}
+	}
+
+	//issues that are not reported anymore
+	for k, ti := range t.issues {
+		_, ok := current[k]
+		if !ok {
+			delete(t.issues, k)
+			ti.issue.Score = 0
+			events = append(events, ti.event(IssueResolved, now))
+		}
+	}
+
+	sortIssueEvents(events)
+	return events
+}
+
+//openIssues the issues that are open after the last update. Their Typ is the last transition emitted for them
+func (t *issueTracker) openIssues(now time.Time) []IssueEvent {
+	open := make([]IssueEvent, 0)
+	for _, ti := range t.issues {
+		open = append(open, ti.event(ti.lastTyp, now))
+	}
+	sortIssueEvents(open)
+	return open
+}
+
+//sortIssueEvents sorts events by issue type, detector id and resource name
+//so that they are always sent in the same order
+func sortIssueEvents(events []IssueEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a := events[i]
+		b := events[j]
+		if a.Issue.Typ != b.Issue.Typ {
+			return a.Issue.Typ < b.Issue.Typ
+		}
+		if a.Issue.ID != b.Issue.ID {
+			return a.Issue.ID < b.Issue.ID
+		}
+		return a.Issue.Res.Name < b.Issue.Res.Name
+	})
+}
+
+//event creates an event for the issue and records typ as its last transition
+func (ti *trackedIssue) event(typ string, now time.Time) IssueEvent {
+	ti.lastTyp = typ
+	return IssueEvent{
+		When:      now,
+		Issue:     ti.issue,
+		Typ:       typ,
+		FirstSeen: ti.firstSeen,
+		PeakScore: ti.peakScore,
+		Duration:  now.Sub(ti.firstSeen),
+	}
+}
@@ -0,0 +1,112 @@
+package perfstat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueTrackerLifecycle(t *testing.T) {
+	tr := newIssueTracker()
+	t0 := time.Now()
+	dr := detectors.DetectionResult{Typ: "bottleneck", ID: "cpu-low-idle", Res: detectors.Resource{Name: "cpu:all"}}
+
+	//not enough data
+	dr.Score = -1
+	evts := tr.update([]detectors.DetectionResult{dr}, t0)
+	assert.Equal(t, 0, len(evts))
+
+	dr.Score = 0.3
+	evts = tr.update([]detectors.DetectionResult{dr}, t0)
+	assert.Equal(t, 1, len(evts))
+	assert.Equal(t, IssueOpened, evts[0].Typ)
+	assert.Equal(t, t0, evts[0].FirstSeen)
+
+	//small changes are not reported
+	dr.Score = 0.35
+	evts = tr.update([]detectors.DetectionResult{dr}, t0.Add(1*time.Second))
+	assert.Equal(t, 0, len(evts))
+
+	dr.Score = 0.8
+	evts = tr.update([]detectors.DetectionResult{dr}, t0.Add(2*time.Second))
+	assert.Equal(t, 1, len(evts))
+	assert.Equal(t, IssueEscalated, evts[0].Typ)
+	assert.InDelta(t, 0.8, evts[0].PeakScore, 0.001)
+
+	dr.Score = 0.5
+	evts = tr.update([]detectors.DetectionResult{dr}, t0.Add(3*time.Second))
+	assert.Equal(t, 1, len(evts))
+	assert.Equal(t, IssueDeescalated, evts[0].Typ)
+	assert.InDelta(t, 0.8, evts[0].PeakScore, 0.001)
+
+	dr.Score = 0
+	evts = tr.update([]detectors.DetectionResult{dr}, t0.Add(4*time.Second))
+	assert.Equal(t, 1, len(evts))
+	assert.Equal(t, IssueResolved, evts[0].Typ)
+	assert.Equal(t, 4*time.Second, evts[0].Duration)
+	assert.InDelta(t, 0.8, evts[0].PeakScore, 0.001)
+}
+
+func TestIssueTrackerResolveMissing(t *testing.T) {
+	tr := newIssueTracker()
+	t0 := time.Now()
+	d1 := detectors.DetectionResult{Typ: "risk", ID: "disk-low-space", Score: 0.5, Res: detectors.Resource{Name: "partition:/"}}
+	d2 := detectors.DetectionResult{Typ: "risk", ID: "disk-low-space", Score: 0.7, Res: detectors.Resource{Name: "partition:/data"}}
+
+	evts := tr.update([]detectors.DetectionResult{d1, d2}, t0)
+	assert.Equal(t, 2, len(evts))
+
+	evts = tr.update([]detectors.DetectionResult{d2}, t0.Add(1*time.Second))
+	assert.Equal(t, 1, len(evts))
+	assert.Equal(t, IssueResolved, evts[0].Typ)
+	assert.Equal(t, "partition:/", evts[0].Issue.Res.Name)
+}
+
+func TestIssueTrackerOpenIssues(t *testing.T) {
+	tr := newIssueTracker()
+	t0 := time.Now()
+	dr := detectors.DetectionResult{Typ: "bottleneck", ID: "cpu-low-idle", Res: detectors.Resource{Name: "cpu:all"}, Score: 0.3}
+	tr.update([]detectors.DetectionResult{dr}, t0)
+
+	//small changes don't generate events, but are in the open issues
+	dr.Score = 0.35
+	tr.update([]detectors.DetectionResult{dr}, t0.Add(1*time.Second))
+	open := tr.openIssues(t0.Add(1 * time.Second))
+	if assert.Equal(t, 1, len(open)) {
+		assert.Equal(t, IssueOpened, open[0].Typ)
+		assert.Equal(t, 0.35, open[0].Issue.Score)
+		assert.Equal(t, 1*time.Second, open[0].Duration)
+	}
+
+	dr.Score = 0
+	tr.update([]detectors.DetectionResult{dr}, t0.Add(2*time.Second))
+	assert.Equal(t, 0, len(tr.openIssues(t0.Add(2*time.Second))))
+}
+
+func TestIssueTrackerEventsOrder(t *testing.T) {
+	tr := newIssueTracker()
+	t0 := time.Now()
+	results := []detectors.DetectionResult{
+		{Typ: "risk", ID: "disk-low-space", Score: 0.5, Res: detectors.Resource{Name: "partition:/data"}},
+		{Typ: "bottleneck", ID: "mem-low", Score: 0.5, Res: detectors.Resource{Name: "mem:ram"}},
+		{Typ: "risk", ID: "disk-low-space", Score: 0.5, Res: detectors.Resource{Name: "partition:/"}},
+		{Typ: "bottleneck", ID: "cpu-low-idle", Score: 0.5, Res: detectors.Resource{Name: "cpu:all"}},
+	}
+
+	//the same order in every run, even though issues are kept in a map
+	evts := tr.update(results, t0)
+	names := make([]string, 0)
+	for _, e := range evts {
+		names = append(names, e.Issue.Res.Name)
+	}
+	assert.Equal(t, []string{"cpu:all", "mem:ram", "partition:/", "partition:/data"}, names)
+
+	open := tr.openIssues(t0)
+	names = make([]string, 0)
+	for _, e := range open {
+		names = append(names, e.Issue.Res.Name)
+	}
+	assert.Equal(t, []string{"cpu:all", "mem:ram", "partition:/", "partition:/data"}, names)
+}
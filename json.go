@@ -0,0 +1,94 @@
+package perfstat
+
+import (
+	"math"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+)
+
+//JSONSchemaVersion version of the JSON documents generated from detection results
+//It is incremented when fields are renamed or removed. New fields may be added without changing it
+const JSONSchemaVersion = 1
+
+const (
+	//JSONKindSnapshot results of a single detection run
+	JSONKindSnapshot = "snapshot"
+	//JSONKindTick results of a detection run while streaming
+	JSONKindTick = "tick"
+	//JSONKindEvent an issue lifecycle transition while streaming
+	JSONKindEvent = "event"
+)
+
+//JSONResults document with detection results
+type JSONResults struct {
+	SchemaVersion int                         `json:"schemaVersion"`
+	Kind          string                      `json:"kind"`
+	Host          string                      `json:"host"`
+	When          time.Time                   `json:"when"`
+	Results       []detectors.DetectionResult `json:"results"`
+}
+
+//JSONEvent document with an issue lifecycle transition
+type JSONEvent struct {
+	SchemaVersion int                       `json:"schemaVersion"`
+	Kind          string                    `json:"kind"`
+	Host          string                    `json:"host"`
+	When          time.Time                 `json:"when"`
+	Event         string                    `json:"event"`
+	FirstSeen     time.Time                 `json:"firstSeen"`
+	PeakScore     float64                   `json:"peakScore"`
+	DurationSecs  float64                   `json:"durationSeconds"`
+	Issue         detectors.DetectionResult `json:"issue"`
+}
+
+//NewJSONResults creates a document with detection results of kind JSONKindSnapshot or JSONKindTick
+func NewJSONResults(kind string, host string, results []detectors.DetectionResult) JSONResults {
+	rs := make([]detectors.DetectionResult, 0)
+	for _, r := range results {
+		rs = append(rs, jsonSafeResult(r))
+	}
+	return JSONResults{
+		SchemaVersion: JSONSchemaVersion,
+		Kind:          kind,
+		Host:          host,
+		When:          time.Now(),
+		Results:       rs,
+	}
+}
+
+//NewJSONEvent creates a document with an issue lifecycle transition
+func NewJSONEvent(host string, e IssueEvent) JSONEvent {
+	return JSONEvent{
+		SchemaVersion: JSONSchemaVersion,
+		Kind:          JSONKindEvent,
+		Host:          host,
+		When:          e.When,
+		Event:         e.Typ,
+		FirstSeen:     e.FirstSeen,
+		PeakScore:     jsonSafeFloat(e.PeakScore),
+		DurationSecs:  e.Duration.Seconds(),
+		Issue:         jsonSafeResult(e.Issue),
+	}
+}
+
+//jsonSafeResult replaces values that can't be represented in JSON (NaN, Inf)
+//and nil related resources so that they are serialized as []
+func jsonSafeResult(r detectors.DetectionResult) detectors.DetectionResult {
+	r.Score = jsonSafeFloat(r.Score)
+	r.Res.PropertyValue = jsonSafeFloat(r.Res.PropertyValue)
+	related := make([]detectors.Resource, 0)
+	for _, rr := range r.Related {
+		rr.PropertyValue = jsonSafeFloat(rr.PropertyValue)
+		related = append(related, rr)
+	}
+	r.Related = related
+	return r
+}
+
+func jsonSafeFloat(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,69 @@
+package perfstat
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONResultsSchema(t *testing.T) {
+	r := detectors.DetectionResult{
+		Typ:     "bottleneck",
+		ID:      "disk-limit-wbps",
+		Score:   0.7,
+		Message: "msg",
+		Res:     detectors.Resource{Typ: "disk", Name: "disk:sda", PropertyName: "write-bps", PropertyValue: math.NaN()},
+		Related: []detectors.Resource{{Typ: "process", Name: "dd[123]", PropertyName: "disk-write-bps", PropertyValue: 1000}},
+		InfoURL: "http://info",
+	}
+
+	b, err := json.Marshal(NewJSONResults(JSONKindSnapshot, "host1", []detectors.DetectionResult{r}))
+	assert.Nil(t, err)
+
+	m := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(b, &m))
+	assert.Equal(t, float64(JSONSchemaVersion), m["schemaVersion"])
+	assert.Equal(t, "snapshot", m["kind"])
+	assert.Equal(t, "host1", m["host"])
+
+	rs := m["results"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "bottleneck", rs["type"])
+	assert.Equal(t, "disk-limit-wbps", rs["id"])
+	assert.Equal(t, 0.7, rs["score"])
+	assert.Equal(t, "msg", rs["message"])
+	assert.Equal(t, "http://info", rs["infoURL"])
+	assert.Contains(t, rs, "when")
+	res := rs["resource"].(map[string]interface{})
+	assert.Equal(t, "disk:sda", res["name"])
+	assert.Equal(t, 0.0, res["propertyValue"])
+	rel := rs["related"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "dd[123]", rel["name"])
+	assert.Equal(t, "disk-write-bps", rel["propertyName"])
+}
+
+func TestJSONEventSchema(t *testing.T) {
+	now := time.Now()
+	e := IssueEvent{
+		When:      now,
+		Typ:       IssueResolved,
+		FirstSeen: now.Add(-90 * time.Second),
+		PeakScore: 0.9,
+		Duration:  90 * time.Second,
+		Issue:     detectors.DetectionResult{Typ: "risk", ID: "mem-low"},
+	}
+
+	b, err := json.Marshal(NewJSONEvent("host1", e))
+	assert.Nil(t, err)
+
+	m := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(b, &m))
+	assert.Equal(t, "event", m["kind"])
+	assert.Equal(t, "resolved", m["event"])
+	assert.Equal(t, 0.9, m["peakScore"])
+	assert.Equal(t, 90.0, m["durationSeconds"])
+	assert.Equal(t, []interface{}{}, m["issue"].(map[string]interface{})["related"])
+}
@@ -0,0 +1,334 @@
+package perfstat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/flaviostutz/perfstat/stats"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	//NotificationFiring an issue score got to the destination min score
+	NotificationFiring = "firing"
+	//NotificationResolved a notified issue was resolved or its score dropped below the destination min score
+	NotificationResolved = "resolved"
+)
+
+//Notification data sent to webhooks. It is also the data available to webhook templates
+type Notification struct {
+	//Status firing or resolved
+	Status string `json:"status"`
+	//Host where the issue was detected
+	Host string `json:"host"`
+	//Summary one line description of the notification
+	Summary string `json:"summary"`
+	//Event issue lifecycle transition that triggered this notification
+	Event JSONEvent `json:"event"`
+}
+
+//Notifier sends notifications to webhooks when issues cross score thresholds
+type Notifier struct {
+	destinations []*webhookDestination
+}
+
+type webhookDestination struct {
+	opt      detectors.WebhookOptions
+	tmpl     *template.Template
+	client   *http.Client
+	queue    chan Notification
+	firing   map[string]IssueEvent
+	lastSent map[string]time.Time
+	sent     []time.Time
+	host     string
+}
+
+//StartNotifier starts sending notifications to webhooks for the issues detected in ps
+//Issue events are handled in the order they happened and the scores of open issues
+//are checked against the destinations thresholds after every detection round
+func StartNotifier(ctx context.Context, ps *Perfstat, webhooks []detectors.WebhookOptions) (*Notifier, error) {
+	n, err := newNotifier(ctx, webhooks)
+	if err != nil {
+		return nil, err
+	}
+	w := ps.watch()
+	go func() {
+		defer ps.unwatch(w)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case r := <-w.rounds:
+				n.update(r, time.Now())
+			}
+		}
+	}()
+	return n, nil
+}
+
+func newNotifier(ctx context.Context, webhooks []detectors.WebhookOptions) (*Notifier, error) {
+	hn, err := stats.Hostname()
+	if err != nil {
+		logrus.Debugf("Couldn't get host name. err=%s", err)
+	}
+
+	n := &Notifier{}
+	for _, w := range webhooks {
+		w = w.WithDefaults()
+		d := &webhookDestination{
+			opt:      w,
+			client:   &http.Client{Timeout: w.Timeout},
+			queue:    make(chan Notification, 100),
+			firing:   make(map[string]IssueEvent),
+			lastSent: make(map[string]time.Time),
+			host:     hn,
+		}
+		d.tmpl, err = w.ParseTemplate()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid webhook template for %s. err=%s", w.URL, err)
+		}
+		go d.sendLoop(ctx)
+		n.destinations = append(n.destinations, d)
+	}
+	return n, nil
+}
+
+//update decides which destinations should be notified about the issue events and open issues of a detection round
+func (n *Notifier) update(r issueRound, now time.Time) {
+	for _, d := range n.destinations {
+		d.update(r, now)
+	}
+}
+
+func (d *webhookDestination) update(r issueRound, now time.Time) {
+	//lifecycle transitions, in the order they happened
+	checked := make(map[string]bool)
+	for _, e := range r.events {
+		if !d.acceptsGroup(e.Issue.ID) {
+			continue
+		}
+		k := issueKey(e.Issue)
+		checked[k] = true
+		if e.Typ == IssueResolved {
+			if _, firing := d.firing[k]; firing {
+				d.resolve(k, e, now)
+			}
+			continue
+		}
+		d.check(k, e, now)
+	}
+
+	//open issues whose scores changed without generating events
+	seen := make(map[string]bool)
+	for _, e := range r.open {
+		if !d.acceptsGroup(e.Issue.ID) {
+			continue
+		}
+		k := issueKey(e.Issue)
+		seen[k] = true
+		if !checked[k] {
+			d.check(k, e, now)
+		}
+	}
+
+	//issues closed in rounds that were dropped
+	for k, e := range d.firing {
+		if !seen[k] && !checked[k] {
+			e.Typ = IssueResolved
+			e.Issue.Score = 0
+			d.resolve(k, e, now)
+		}
+	}
+}
+
+//check sends a firing notification when an open issue score gets to the destination min score
+//and a resolved notification when it drops below it
+func (d *webhookDestination) check(k string, e IssueEvent, now time.Time) {
+	_, firing := d.firing[k]
+	above := e.Issue.Score >= *d.opt.MinScore
+
+	if above && firing {
+		d.firing[k] = e
+		return
+	}
+
+	if above {
+		//issues that can't be sent now are checked again in the next round
+		last, ok := d.lastSent[k]
+		if ok && now.Sub(last) < d.opt.MinInterval {
+			logrus.Debugf("Webhook %s: issue %s notified recently. Delaying", d.opt.URL, k)
+			return
+		}
+		if !d.allowRate(now) {
+			logrus.Debugf("Webhook %s: more than %d notifications per minute. Delaying notification for %s", d.opt.URL, *d.opt.MaxPerMinute, k)
+			return
+		}
+		d.firing[k] = e
+		d.lastSent[k] = now
+		d.enqueue(d.notification(NotificationFiring, e))
+		return
+	}
+
+	if firing {
+		d.resolve(k, e, now)
+	}
+}
+
+//resolve sends a resolved notification for a firing issue
+//resolved messages are not rate limited, so that firing notifications are always closed
+func (d *webhookDestination) resolve(k string, e IssueEvent, now time.Time) {
+	delete(d.firing, k)
+	e.Duration = now.Sub(e.FirstSeen)
+	d.enqueue(d.notification(NotificationResolved, e))
+}
+
+func (d *webhookDestination) acceptsGroup(id string) bool {
+	if len(d.opt.Groups) == 0 {
+		return true
+	}
+	for _, g := range d.opt.Groups {
+		if detectors.GroupFromID(id) == g {
+			return true
+		}
+	}
+	return false
+}
+
+//allowRate checks if another notification can be sent in the last minute window
+func (d *webhookDestination) allowRate(now time.Time) bool {
+	if *d.opt.MaxPerMinute == 0 {
+		return true
+	}
+	sent := make([]time.Time, 0)
+	for _, t := range d.sent {
+		if now.Sub(t) < time.Minute {
+			sent = append(sent, t)
+		}
+	}
+	d.sent = sent
+	if len(d.sent) >= *d.opt.MaxPerMinute {
+		return false
+	}
+	d.sent = append(d.sent, now)
+	return true
+}
+
+func (d *webhookDestination) enqueue(nt Notification) {
+	select {
+	case d.queue <- nt:
+	default:
+		logrus.Warnf("Webhook %s: too many pending notifications. Dropping %s", d.opt.URL, nt.Summary)
+	}
+}
+
+func (d *webhookDestination) notification(status string, e IssueEvent) Notification {
+	_, value := formatPropertyValue(e.Issue.Res)
+	summary := fmt.Sprintf("[%s] %s %s on %s: %s=%s score=%.2f", strings.ToUpper(status), e.Issue.Typ, e.Issue.ID, d.host, e.Issue.Res.Name, value, e.Issue.Score)
+	if status == NotificationResolved {
+		summary = fmt.Sprintf("[%s] %s %s on %s: %s. peak score=%.2f duration=%s", strings.ToUpper(status), e.Issue.Typ, e.Issue.ID, d.host, e.Issue.Res.Name, e.PeakScore, e.Duration.Round(time.Second))
+	}
+	return Notification{
+		Status:  status,
+		Host:    d.host,
+		Summary: summary,
+		Event:   NewJSONEvent(d.host, e),
+	}
+}
+
+func (d *webhookDestination) sendLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case nt := <-d.queue:
+			d.send(ctx, nt)
+		}
+	}
+}
+
+//send posts a notification, retrying with exponential backoff on network errors, 429 and 5xx responses
+func (d *webhookDestination) send(ctx context.Context, nt Notification) {
+	body, err := d.payload(nt)
+	if err != nil {
+		logrus.Warnf("Webhook %s: error rendering payload. err=%s", d.opt.URL, err)
+		return
+	}
+
+	backoff := d.opt.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := d.post(body)
+		if err == nil {
+			logrus.Debugf("Webhook %s: sent %s", d.opt.URL, nt.Summary)
+			return
+		}
+		if !retry || attempt >= *d.opt.MaxRetries {
+			logrus.Warnf("Webhook %s: couldn't send notification after %d attempts. err=%s", d.opt.URL, attempt+1, err)
+			return
+		}
+		logrus.Debugf("Webhook %s: error sending notification. Retrying in %s. err=%s", d.opt.URL, backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = backoff * 2
+	}
+}
+
+func (d *webhookDestination) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", d.opt.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range d.opt.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+}
+
+func (d *webhookDestination) payload(nt Notification) ([]byte, error) {
+	if d.tmpl != nil {
+		buf := &bytes.Buffer{}
+		err := d.tmpl.Execute(buf, nt)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if d.opt.Format == "slack" {
+		return json.Marshal(map[string]string{"text": nt.Summary})
+	}
+	return json.Marshal(nt)
+}
+
+func formatPropertyValue(res detectors.Resource) (name string, value string) {
+	idx := strings.LastIndex(res.PropertyName, "-")
+	if idx == -1 {
+		return res.PropertyName, fmt.Sprintf("%.2f", res.PropertyValue)
+	}
+	unit := res.PropertyName[idx+1:]
+	if unit == "perc" {
+		return res.PropertyName[:idx], fmt.Sprintf("%.0f%%", res.PropertyValue*100)
+	}
+	return res.PropertyName[:idx], fmt.Sprintf("%.2f%s", res.PropertyValue, unit)
+}
@@ -0,0 +1,248 @@
+package perfstat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/perfstat/detectors"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookStandIn struct {
+	server   *httptest.Server
+	bodies   []string
+	failures int
+	m        sync.Mutex
+}
+
+func newWebhookStandIn(failures int) *webhookStandIn {
+	s := &webhookStandIn{failures: failures}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.m.Lock()
+		defer s.m.Unlock()
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(b))
+	}))
+	return s
+}
+
+func (s *webhookStandIn) received() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string{}, s.bodies...)
+}
+
+func openIssue(id string, score float64) IssueEvent {
+	return IssueEvent{
+		When:  time.Now(),
+		Typ:   IssueOpened,
+		Issue: detectors.DetectionResult{Typ: "bottleneck", ID: id, Score: score, Res: detectors.Resource{Name: "disk:sda", PropertyName: "write-bps", PropertyValue: 1000}},
+	}
+}
+
+func minScore(v float64) *float64 {
+	return &v
+}
+
+func intOpt(v int) *int {
+	return &v
+}
+
+func TestNotifierFiringAndResolved(t *testing.T) {
+	s := newWebhookStandIn(0)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL, MinScore: minScore(0.6), Groups: []string{"disk"}}})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.3), openIssue("cpu-low-idle", 0.9)}}, now)
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.7)}}, now)
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.9)}}, now)
+	n.update(issueRound{open: []IssueEvent{}}, now.Add(time.Minute))
+
+	assert.Eventually(t, func() bool { return len(s.received()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	nt := Notification{}
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[0]), &nt))
+	assert.Equal(t, NotificationFiring, nt.Status)
+	assert.Equal(t, "disk-limit-wbps", nt.Event.Issue.ID)
+	assert.Equal(t, 0.7, nt.Event.Issue.Score)
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[1]), &nt))
+	assert.Equal(t, NotificationResolved, nt.Status)
+
+	//flapping issue is not notified again before min interval
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.9)}}, now.Add(2*time.Minute))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 2, len(s.received()))
+	n.update(issueRound{open: []IssueEvent{}}, now.Add(3*time.Minute))
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.9)}}, now.Add(6*time.Minute))
+	assert.Eventually(t, func() bool { return len(s.received()) == 3 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifierThresholdEveryRound(t *testing.T) {
+	s := newWebhookStandIn(0)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL}})
+	assert.Nil(t, err)
+
+	//slowly crosses the default min score without generating issue events
+	now := time.Now()
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.45)}}, now)
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.48)}}, now.Add(1*time.Second))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 0, len(s.received()))
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.51)}}, now.Add(2*time.Second))
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.54)}}, now.Add(3*time.Second))
+	assert.Eventually(t, func() bool { return len(s.received()) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	//drops back below it while still open
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.49)}}, now.Add(4*time.Second))
+	assert.Eventually(t, func() bool { return len(s.received()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	nt := Notification{}
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[1]), &nt))
+	assert.Equal(t, NotificationResolved, nt.Status)
+
+	//held back by min interval and sent in a later round while still open
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.6)}}, now.Add(1*time.Minute))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 2, len(s.received()))
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.6)}}, now.Add(6*time.Minute))
+	assert.Eventually(t, func() bool { return len(s.received()) == 3 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifierIssueEvents(t *testing.T) {
+	s := newWebhookStandIn(0)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL, MinInterval: time.Nanosecond}})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	opened := openIssue("disk-limit-wbps", 0.8)
+	n.update(issueRound{events: []IssueEvent{opened}, open: []IssueEvent{opened}}, now)
+	resolved := opened
+	resolved.Typ = IssueResolved
+	resolved.PeakScore = 0.9
+	n.update(issueRound{events: []IssueEvent{resolved}}, now.Add(time.Second))
+	n.update(issueRound{events: []IssueEvent{opened}, open: []IssueEvent{opened}}, now.Add(2*time.Second))
+
+	assert.Eventually(t, func() bool { return len(s.received()) == 3 }, 2*time.Second, 10*time.Millisecond)
+	status := []string{}
+	for _, b := range s.received() {
+		nt := Notification{}
+		assert.Nil(t, json.Unmarshal([]byte(b), &nt))
+		status = append(status, nt.Status)
+	}
+	assert.Equal(t, []string{NotificationFiring, NotificationResolved, NotificationFiring}, status)
+	nt := Notification{}
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[1]), &nt))
+	assert.Equal(t, IssueResolved, nt.Event.Event)
+	assert.Equal(t, 0.9, nt.Event.PeakScore)
+
+	//issues resolved before getting to the min score are not notified
+	low := openIssue("disk-limit-rbps", 0.2)
+	n.update(issueRound{events: []IssueEvent{low}, open: []IssueEvent{opened, low}}, now.Add(3*time.Second))
+	low.Typ = IssueResolved
+	n.update(issueRound{events: []IssueEvent{low}, open: []IssueEvent{opened}}, now.Add(4*time.Second))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 3, len(s.received()))
+}
+
+func TestNotifierZeroMinScore(t *testing.T) {
+	s := newWebhookStandIn(0)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL, MinScore: minScore(0)}})
+	assert.Nil(t, err)
+
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.05)}}, time.Now())
+	assert.Eventually(t, func() bool { return len(s.received()) == 1 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifierRetryAndTemplate(t *testing.T) {
+	s := newWebhookStandIn(2)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{
+		URL:          s.server.URL,
+		Template:     `{"msg": {{json .Summary}}, "status": "{{.Status}}"}`,
+		RetryBackoff: 10 * time.Millisecond,
+	}})
+	assert.Nil(t, err)
+
+	n.update(issueRound{open: []IssueEvent{openIssue("disk-limit-wbps", 0.8)}}, time.Now())
+	assert.Eventually(t, func() bool { return len(s.received()) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	m := make(map[string]string)
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[0]), &m))
+	assert.Equal(t, "firing", m["status"])
+	assert.Contains(t, m["msg"], "disk-limit-wbps")
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	s := newWebhookStandIn(0)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL, Format: "slack", MaxPerMinute: intOpt(2)}})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	open := []IssueEvent{openIssue("disk-limit-wbps", 0.8), openIssue("disk-limit-rbps", 0.8), openIssue("disk-limit-wops", 0.8)}
+	n.update(issueRound{open: open}, now)
+	assert.Eventually(t, func() bool { return len(s.received()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 2, len(s.received()))
+
+	m := make(map[string]string)
+	assert.Nil(t, json.Unmarshal([]byte(s.received()[0]), &m))
+	assert.Contains(t, m["text"], "[FIRING]")
+
+	//the delayed issue is sent when the rate allows
+	n.update(issueRound{open: open}, now.Add(61*time.Second))
+	assert.Eventually(t, func() bool { return len(s.received()) == 3 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifierZeroRateAndRetries(t *testing.T) {
+	s := newWebhookStandIn(1)
+	defer s.server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := newNotifier(ctx, []detectors.WebhookOptions{{URL: s.server.URL, MaxPerMinute: intOpt(0), MaxRetries: intOpt(0), RetryBackoff: 10 * time.Millisecond}})
+	assert.Nil(t, err)
+
+	//the first notification fails and is not retried. the others are not rate limited
+	open := []IssueEvent{}
+	for i := 0; i < 30; i++ {
+		open = append(open, openIssue(fmt.Sprintf("disk-limit-%d", i), 0.8))
+	}
+	n.update(issueRound{open: open}, time.Now())
+	assert.Eventually(t, func() bool { return len(s.received()) == 29 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 29, len(s.received()))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/coryb/sorty"
@@ -12,9 +13,11 @@ import (
 	"github.com/flaviostutz/perfstat/stats"
 	"github.com/flaviostutz/signalutils"
 	"github.com/sirupsen/logrus"
-	"github.com/yaacov/observer/observer"
 )
 
+//watcherBufferSize detection rounds kept for a watcher that is not reading
+const watcherBufferSize = 100
+
 //Perfstat performance analyser
 type Perfstat struct {
 	opt          detectors.Options
@@ -22,19 +25,31 @@ type Perfstat struct {
 	processStats *stats.ProcessStats
 	workerCancel context.CancelFunc
 	curResults   []detectors.DetectionResult
-	observer     observer.Observer
+	gater        *detectors.ScoreGater
+	tracker      *issueTracker
+	watchers     map[*issueWatcher]bool
+	watchersM    sync.Mutex
+}
+
+//issueRound issue events and open issues after a detection round
+type issueRound struct {
+	events []IssueEvent
+	open   []IssueEvent
 }
 
-type IssueEvent struct {
-	When  time.Time
-	Issue detectors.DetectionResult
-	Typ   string
+//issueWatcher receives detection rounds in the order they happened
+type issueWatcher struct {
+	rounds chan issueRound
+	done   chan struct{}
 }
 
 //Start initializes a new Perfstat utility
 func Start(ctx context.Context, opt detectors.Options) *Perfstat {
 	p := &Perfstat{
-		opt: opt,
+		opt:      opt,
+		gater:    detectors.NewScoreGater(),
+		tracker:  newIssueTracker(),
+		watchers: make(map[*issueWatcher]bool),
 	}
 
 	logrus.Debugf("Starting detectors")
@@ -48,11 +63,14 @@ func Start(ctx context.Context, opt detectors.Options) *Perfstat {
 			return err
 		}
 
-		p.observer = observer.Observer{}
-		p.observer.Open()
-		p.observer.Emit(result)
-
+		result = p.gater.Apply(&detectors.Opt, result, time.Now())
 		p.curResults = result
+
+		now := time.Now()
+		p.publish(issueRound{
+			events: p.tracker.update(result, now),
+			open:   p.tracker.openIssues(now),
+		})
 		return nil
 	}, opt.DefaultSampleFreq/2, opt.DefaultSampleFreq, true)
 
@@ -64,11 +82,64 @@ func (p *Perfstat) SetLogLevel(level logrus.Level) {
 	detectors.SetLogLevel(level)
 }
 
-func (p *Perfstat) Watch(issueEvents chan IssueEvent) {
-	p.observer.AddListener(func(e interface{}) {
-		iss := e.(IssueEvent)
-		issueEvents <- iss
-	})
+//Watch sends issue lifecycle events (opened, escalated, de-escalated, resolved)
+//to issueEvents in the order they are detected. Call the returned func to stop watching
+func (p *Perfstat) Watch(issueEvents chan IssueEvent) (unwatch func()) {
+	w := p.watch()
+	go func() {
+		for {
+			select {
+			case <-w.done:
+				return
+			case r := <-w.rounds:
+				for _, e := range r.events {
+					select {
+					case issueEvents <- e:
+					case <-w.done:
+						return
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		p.unwatch(w)
+	}
+}
+
+//watch registers a new watcher for the next detection rounds
+func (p *Perfstat) watch() *issueWatcher {
+	p.watchersM.Lock()
+	defer p.watchersM.Unlock()
+	w := &issueWatcher{
+		rounds: make(chan issueRound, watcherBufferSize),
+		done:   make(chan struct{}),
+	}
+	p.watchers[w] = true
+	return w
+}
+
+func (p *Perfstat) unwatch(w *issueWatcher) {
+	p.watchersM.Lock()
+	defer p.watchersM.Unlock()
+	if p.watchers[w] {
+		delete(p.watchers, w)
+		close(w.done)
+	}
+}
+
+//publish sends a detection round to all watchers. Rounds are dropped for watchers
+//that are not reading, so that detection is never blocked by them
+func (p *Perfstat) publish(r issueRound) {
+	p.watchersM.Lock()
+	defer p.watchersM.Unlock()
+	for w := range p.watchers {
+		select {
+		case w.rounds <- r:
+		default:
+			logrus.Warnf("Issue watcher is not reading. Dropping detection round")
+		}
+	}
 }
 
 //TopCriticity returns the most important items found in system
@@ -163,7 +234,11 @@ func (p *Perfstat) DetectNow() ([]detectors.DetectionResult, error) {
 		// for _, iss := range r {
 		// 	logrus.Debugf("RESULT: %s", iss.String())
 		// }
-		results = append(results, r...)
+		for _, iss := range r {
+			if detectors.Opt.DetectorEnabled(iss.ID) {
+				results = append(results, iss)
+			}
+		}
 	}
 	return results, nil
 }
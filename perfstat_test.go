@@ -31,6 +31,36 @@ func TestDetectNow(t *testing.T) {
 	checkOneEqual(t, issues, "bottleneck", "cpu-low")
 }
 
+func TestWatchOrder(t *testing.T) {
+	p := &Perfstat{watchers: make(map[*issueWatcher]bool)}
+	events := make(chan IssueEvent)
+	unwatch := p.Watch(events)
+
+	for i := 0; i < 50; i++ {
+		p.publish(issueRound{events: []IssueEvent{
+			{Typ: IssueOpened, PeakScore: float64(i)},
+			{Typ: IssueResolved, PeakScore: float64(i)},
+		}})
+	}
+	for i := 0; i < 50; i++ {
+		e := <-events
+		assert.Equal(t, IssueOpened, e.Typ)
+		assert.Equal(t, float64(i), e.PeakScore)
+		e = <-events
+		assert.Equal(t, IssueResolved, e.Typ)
+		assert.Equal(t, float64(i), e.PeakScore)
+	}
+
+	//a watcher that is not reading doesn't block detection rounds
+	for i := 0; i < 2*watcherBufferSize; i++ {
+		p.publish(issueRound{events: []IssueEvent{{Typ: IssueOpened}}})
+	}
+
+	unwatch()
+	assert.Equal(t, 0, len(p.watchers))
+	p.publish(issueRound{events: []IssueEvent{{Typ: IssueOpened}}})
+}
+
 func TestRollingDetections(t *testing.T) {
 	opt := detectors.NewOptions()
 	opt.CPULoadAvgDuration = 10 * time.Second
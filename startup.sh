@@ -33,6 +33,6 @@ if [ "$RUN_TESTS" == "true" ]; then
 else
 
     echo "Starting Perfstat Prometheus Exporter..."
-    perfstat prometheus
+    perfstat prometheus --host-root "$HOST_ROOT"
 
 fi
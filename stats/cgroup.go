@@ -0,0 +1,289 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+//CgroupStats resource usage and limits of containers, taken from their cgroup v2 files
+type CgroupStats struct {
+	//Cgroups container cgroups by cgroup path
+	Cgroups           map[string]*CgroupMetrics
+	timeseriesMaxSpan time.Duration
+	lastCleanupTime   time.Time
+	containerNames    map[string]string
+	source            Source
+}
+
+//CgroupMetrics resource usage of a container
+type CgroupMetrics struct {
+	Path          string
+	ContainerID   string
+	ContainerName string
+	PodUID        string
+	LastSeen      time.Time
+	//CPUUsage cpu time used by the container in seconds
+	CPUUsage      signalutils.TimeseriesCounterRate
+	NrPeriods     signalutils.TimeseriesCounterRate
+	NrThrottled   signalutils.TimeseriesCounterRate
+	ThrottledTime signalutils.TimeseriesCounterRate
+	MemoryCurrent signalutils.Timeseries
+	//MemoryWorkingSet memory.current without inactive page cache, which can be reclaimed before the limit is hit
+	MemoryWorkingSet signalutils.Timeseries
+	//MemoryMax memory limit in bytes. 0 when not limited
+	MemoryMax uint64
+	//MemoryOOMKill cumulative number of processes killed by the OOM killer in this cgroup
+	//It keeps growing when the counter is reset by container restarts
+	MemoryOOMKill signalutils.Timeseries
+	IOReadBytes   signalutils.TimeseriesCounterRate
+	IOWriteBytes  signalutils.TimeseriesCounterRate
+	IOReadOps     signalutils.TimeseriesCounterRate
+	IOWriteOps    signalutils.TimeseriesCounterRate
+	PidsCurrent   signalutils.Timeseries
+	//PidsMax max number of pids. 0 when not limited
+	PidsMax uint64
+	//oomKillOffset kills counted before the oom_kill counter was reset
+	oomKillOffset uint64
+	lastOOMKill   uint64
+}
+
+func NewCgroupStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *CgroupStats {
+	logrus.Tracef("Cgroup Stats: initializing...")
+	c := &CgroupStats{
+		Cgroups:           make(map[string]*CgroupMetrics),
+		timeseriesMaxSpan: timeseriesMaxSpan,
+		containerNames:    make(map[string]string),
+		source:            sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "cgroup", c.cgroupStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Cgroup Stats: running")
+	return c
+}
+
+func (c *CgroupStats) cgroupStep() error {
+	//cleanup containers that are gone to avoid memory leaks
+	if time.Now().Sub(c.lastCleanupTime) > 1*time.Hour {
+		for path, cm := range c.Cgroups {
+			if time.Now().Sub(cm.LastSeen) > 10*time.Minute {
+				delete(c.Cgroups, path)
+				delete(c.containerNames, cm.ContainerID)
+			}
+		}
+		c.lastCleanupTime = time.Now()
+	}
+
+	samples, err := c.source.Cgroups()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range samples {
+		cm, ok := c.Cgroups[s.Path]
+		if !ok {
+			ci := ParseContainerInfo(s.Path)
+			name, ok := c.containerNames[ci.ContainerID]
+			if !ok {
+				name = resolveContainerName(ci.ContainerID)
+				c.containerNames[ci.ContainerID] = name
+			}
+			cm = &CgroupMetrics{
+				Path:             s.Path,
+				ContainerID:      ci.ContainerID,
+				ContainerName:    name,
+				PodUID:           ci.PodUID,
+				CPUUsage:         signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				NrPeriods:        signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				NrThrottled:      signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				ThrottledTime:    signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				MemoryCurrent:    signalutils.NewTimeseries(c.timeseriesMaxSpan),
+				MemoryWorkingSet: signalutils.NewTimeseries(c.timeseriesMaxSpan),
+				MemoryOOMKill:    signalutils.NewTimeseries(c.timeseriesMaxSpan),
+				IOReadBytes:      signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				IOWriteBytes:     signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				IOReadOps:        signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				IOWriteOps:       signalutils.NewTimeseriesCounterRate(c.timeseriesMaxSpan),
+				PidsCurrent:      signalutils.NewTimeseries(c.timeseriesMaxSpan),
+			}
+			c.Cgroups[s.Path] = cm
+		}
+		addCgroupStats(s, cm)
+	}
+
+	return nil
+}
+
+func addCgroupStats(s CgroupSample, cm *CgroupMetrics) {
+	cm.LastSeen = time.Now()
+
+	setCounter(&cm.CPUUsage, float64(s.CPUUsageUsec)/1000000)
+	setCounter(&cm.NrPeriods, float64(s.NrPeriods))
+	setCounter(&cm.NrThrottled, float64(s.NrThrottled))
+	setCounter(&cm.ThrottledTime, float64(s.ThrottledUsec)/1000000)
+
+	cm.MemoryCurrent.Add(float64(s.MemoryCurrent))
+	ws := s.MemoryCurrent
+	if s.MemoryInactiveFile < ws {
+		ws = ws - s.MemoryInactiveFile
+	}
+	cm.MemoryWorkingSet.Add(float64(ws))
+	cm.MemoryMax = s.MemoryMax
+	if s.MemoryEvents.OOMKill < cm.lastOOMKill {
+		//container was restarted. keep kills counted before the restart
+		cm.oomKillOffset += cm.lastOOMKill
+	}
+	cm.lastOOMKill = s.MemoryEvents.OOMKill
+	cm.MemoryOOMKill.Add(float64(cm.oomKillOffset + s.MemoryEvents.OOMKill))
+
+	setCounter(&cm.IOReadBytes, float64(s.IOReadBytes))
+	setCounter(&cm.IOWriteBytes, float64(s.IOWriteBytes))
+	setCounter(&cm.IOReadOps, float64(s.IOReadOps))
+	setCounter(&cm.IOWriteOps, float64(s.IOWriteOps))
+
+	cm.PidsCurrent.Add(float64(s.PidsCurrent))
+	cm.PidsMax = s.PidsMax
+}
+
+//setCounter sets a counter value, discarding older values if the counter was reset
+func setCounter(tcr *signalutils.TimeseriesCounterRate, value float64) {
+	l, ok := tcr.Timeseries.Last()
+	if ok && value < l.Value {
+		tcr.Timeseries.Reset()
+	}
+	tcr.Set(value)
+}
+
+//readCgroups reads stats of container cgroups found in the cgroup v2 hierarchy mounted at root
+//Returns no samples if cgroup v2 is not available
+func readCgroups(root string) ([]CgroupSample, error) {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	if err != nil {
+		logrus.Tracef("cgroup v2 not found at %s. err=%s", root, err)
+		return nil, nil
+	}
+
+	samples := make([]CgroupSample, 0)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			//cgroups may be removed while walking
+			return nil
+		}
+		if !info.IsDir() || path == root {
+			return nil
+		}
+		rel := "/" + filepath.ToSlash(strings.TrimPrefix(path, root+string(filepath.Separator)))
+		if ParseContainerInfo(rel).ContainerID == "" {
+			return nil
+		}
+		samples = append(samples, readCgroup(path, rel))
+		//nested cgroups belong to the same container
+		return filepath.SkipDir
+	})
+	return samples, err
+}
+
+//readCgroup reads cgroup v2 stats files from dir. Files that are not present are ignored
+func readCgroup(dir string, path string) CgroupSample {
+	s := CgroupSample{Path: path}
+
+	cs := readKeyValues(filepath.Join(dir, "cpu.stat"))
+	s.CPUUsageUsec = cs["usage_usec"]
+	s.NrPeriods = cs["nr_periods"]
+	s.NrThrottled = cs["nr_throttled"]
+	s.ThrottledUsec = cs["throttled_usec"]
+
+	s.MemoryCurrent = readLimit(filepath.Join(dir, "memory.current"))
+	s.MemoryMax = readLimit(filepath.Join(dir, "memory.max"))
+	s.MemoryInactiveFile = readKeyValues(filepath.Join(dir, "memory.stat"))["inactive_file"]
+	me := readKeyValues(filepath.Join(dir, "memory.events"))
+	s.MemoryEvents = CgroupMemoryEvents{
+		Low:     me["low"],
+		High:    me["high"],
+		Max:     me["max"],
+		OOM:     me["oom"],
+		OOMKill: me["oom_kill"],
+	}
+
+	s.IOReadBytes, s.IOWriteBytes, s.IOReadOps, s.IOWriteOps = readIOStat(filepath.Join(dir, "io.stat"))
+
+	s.PidsCurrent = readLimit(filepath.Join(dir, "pids.current"))
+	s.PidsMax = readLimit(filepath.Join(dir, "pids.max"))
+	return s
+}
+
+//readKeyValues reads files with "<key> <value>" lines, such as cpu.stat and memory.events
+func readKeyValues(file string) map[string]uint64 {
+	kv := make(map[string]uint64)
+	f, err := os.Open(file)
+	if err != nil {
+		return kv
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		kv[fields[0]] = v
+	}
+	return kv
+}
+
+//readLimit reads files with a single value. "max" (no limit) is returned as 0
+func readLimit(file string) uint64 {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+//readIOStat sums io.stat counters of all devices. Lines are like "8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0"
+func readIOStat(file string) (rbytes uint64, wbytes uint64, rios uint64, wios uint64) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, 0, 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		for _, kv := range strings.Fields(scanner.Text()) {
+			p := strings.SplitN(kv, "=", 2)
+			if len(p) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(p[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch p[0] {
+			case "rbytes":
+				rbytes += v
+			case "wbytes":
+				wbytes += v
+			case "rios":
+				rios += v
+			case "wios":
+				wios += v
+			}
+		}
+	}
+	return rbytes, wbytes, rios, wios
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCgroups(t *testing.T) {
+	samples, err := readCgroups("testdata/hostroot/sys/fs/cgroup")
+	assert.Nil(t, err)
+	if !assert.Equal(t, 1, len(samples)) {
+		return
+	}
+	s := samples[0]
+	assert.Equal(t, "/system.slice/docker-"+dockerID+".scope", s.Path)
+	assert.Equal(t, uint64(5000000), s.CPUUsageUsec)
+	assert.Equal(t, uint64(100), s.NrPeriods)
+	assert.Equal(t, uint64(25), s.NrThrottled)
+	assert.Equal(t, uint64(300000), s.ThrottledUsec)
+	assert.Equal(t, uint64(400000000), s.MemoryCurrent)
+	assert.Equal(t, uint64(536870912), s.MemoryMax)
+	assert.Equal(t, uint64(60000000), s.MemoryInactiveFile)
+	assert.Equal(t, uint64(2), s.MemoryEvents.OOMKill)
+	assert.Equal(t, uint64(12), s.MemoryEvents.Max)
+	assert.Equal(t, uint64(1500), s.IOReadBytes)
+	assert.Equal(t, uint64(2500), s.IOWriteBytes)
+	assert.Equal(t, uint64(15), s.IOReadOps)
+	assert.Equal(t, uint64(25), s.IOWriteOps)
+	assert.Equal(t, uint64(12), s.PidsCurrent)
+	assert.Equal(t, uint64(0), s.PidsMax)
+
+	//cgroup v1 hosts have no container samples
+	samples, err = readCgroups("testdata/hostroot/sys")
+	assert.Nil(t, err)
+	assert.Empty(t, samples)
+}
+
+func TestScriptedCgroupStats(t *testing.T) {
+	path := "/system.slice/docker-" + dockerID + ".scope"
+	tl := Timeline{}
+	for i := 0; i < 50; i++ {
+		oomKill := uint64(0)
+		if i >= 30 {
+			oomKill = 1
+		}
+		//half a cpu, half of the periods throttled
+		tl.Cgroups = append(tl.Cgroups, []CgroupSample{{
+			Path:          path,
+			CPUUsageUsec:  uint64(i) * 50000,
+			NrPeriods:     uint64(i) * 10,
+			NrThrottled:   uint64(i) * 5,
+			MemoryCurrent: 900,
+			MemoryMax:     1000,
+			MemoryEvents:  CgroupMemoryEvents{OOMKill: oomKill},
+		}})
+	}
+
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewCgroupStats(ctx, NewScriptedSource(tl), 60*time.Second, 10)
+
+	time.Sleep(2 * time.Second)
+	cm, ok := s.Cgroups[path]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, dockerID, cm.ContainerID)
+	assert.Equal(t, "billing-api", cm.ContainerName)
+	assert.Equal(t, uint64(1000), cm.MemoryMax)
+
+	cpu, ok := cm.CPUUsage.Rate(1 * time.Second)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, cpu, 0.05)
+	periods, _ := cm.NrPeriods.Rate(1 * time.Second)
+	throttled, _ := cm.NrThrottled.Rate(1 * time.Second)
+	assert.InDelta(t, 0.5, throttled/periods, 0.05)
+}
+
+func TestCgroupOOMKillRestart(t *testing.T) {
+	cm := &CgroupMetrics{
+		CPUUsage:         signalutils.NewTimeseriesCounterRate(time.Minute),
+		NrPeriods:        signalutils.NewTimeseriesCounterRate(time.Minute),
+		NrThrottled:      signalutils.NewTimeseriesCounterRate(time.Minute),
+		ThrottledTime:    signalutils.NewTimeseriesCounterRate(time.Minute),
+		MemoryCurrent:    signalutils.NewTimeseries(time.Minute),
+		MemoryWorkingSet: signalutils.NewTimeseries(time.Minute),
+		MemoryOOMKill:    signalutils.NewTimeseries(time.Minute),
+		IOReadBytes:      signalutils.NewTimeseriesCounterRate(time.Minute),
+		IOWriteBytes:     signalutils.NewTimeseriesCounterRate(time.Minute),
+		IOReadOps:        signalutils.NewTimeseriesCounterRate(time.Minute),
+		IOWriteOps:       signalutils.NewTimeseriesCounterRate(time.Minute),
+		PidsCurrent:      signalutils.NewTimeseries(time.Minute),
+	}
+	//killed twice, restarted and killed again
+	for _, k := range []uint64{0, 1, 2, 0, 1} {
+		addCgroupStats(CgroupSample{MemoryEvents: CgroupMemoryEvents{OOMKill: k}}, cm)
+	}
+	tvs := TimeValuesRange(&cm.MemoryOOMKill, time.Now().Add(-time.Minute), time.Now())
+	if assert.Equal(t, 5, len(tvs)) {
+		assert.Equal(t, 0.0, tvs[0].Value)
+		assert.Equal(t, 3.0, tvs[4].Value)
+	}
+}
+
+func TestCgroupCleanup(t *testing.T) {
+	c := &CgroupStats{
+		Cgroups: map[string]*CgroupMetrics{
+			"/gone": {Path: "/gone", ContainerID: "aaa", LastSeen: time.Now().Add(-11 * time.Minute)},
+		},
+		containerNames: map[string]string{"aaa": "web"},
+		source:         NewScriptedSource(Timeline{}),
+	}
+	assert.Nil(t, c.cgroupStep())
+	assert.Equal(t, 0, len(c.Cgroups))
+	assert.Equal(t, 0, len(c.containerNames))
+}
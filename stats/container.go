@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	//docker: /docker/<id>, /system.slice/docker-<id>.scope; containerd: cri-containerd-<id>.scope; cri-o: crio-<id>.scope; podman: libpod-<id>.scope
+	containerIDRe = regexp.MustCompile(`(?:^|/)(?:docker[-/]|cri-containerd-|crio-|libpod-)?([0-9a-f]{64})(?:\.scope)?(?:/|$)`)
+	//cgroupfs: /kubepods/burstable/pod<uid>/...; systemd: kubepods-burstable-pod<uid with _>.slice
+	podUIDRe = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+)
+
+//ContainerInfo container attribution of a process, taken from its cgroup path
+type ContainerInfo struct {
+	//Cgroup path of the process cgroup. ex.: /system.slice/docker-<id>.scope
+	Cgroup string
+	//ContainerID full container ID. Empty if the process doesn't run in a container
+	ContainerID string
+	//PodUID Kubernetes pod UID. Empty if the process doesn't run in a pod
+	PodUID string
+}
+
+//ParseCgroup gets the cgroup path of a process from the contents of /proc/<pid>/cgroup
+//The unified (v2) hierarchy is used when present, else the v1 memory or cpu controller path
+func ParseCgroup(content string) string {
+	v1 := ""
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			return fields[2]
+		}
+		for _, c := range strings.Split(fields[1], ",") {
+			if c == "memory" || (c == "cpu" && v1 == "") {
+				v1 = fields[2]
+			}
+		}
+	}
+	return v1
+}
+
+//ParseContainerInfo gets the container ID and pod UID from a cgroup path
+func ParseContainerInfo(cgroup string) ContainerInfo {
+	ci := ContainerInfo{Cgroup: cgroup}
+	m := containerIDRe.FindAllStringSubmatch(cgroup, -1)
+	if len(m) > 0 {
+		ci.ContainerID = m[len(m)-1][1]
+	}
+	pm := podUIDRe.FindStringSubmatch(cgroup)
+	if pm != nil {
+		ci.PodUID = strings.Replace(pm[1], "_", "-", -1)
+	}
+	return ci
+}
+
+//ShortContainerID the 12 chars container ID shown by docker
+func ShortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+//resolveContainerName looks for the container name in docker metadata and then in
+//Kubernetes container log names. Defaults to the short container ID
+func resolveContainerName(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	b, err := ioutil.ReadFile(HostVar("lib", "docker", "containers", id, "config.v2.json"))
+	if err == nil {
+		cfg := struct {
+			Name string
+		}{}
+		if json.Unmarshal(b, &cfg) == nil && cfg.Name != "" {
+			return strings.TrimPrefix(cfg.Name, "/")
+		}
+	}
+
+	//kubelet links /var/log/containers/<pod>_<namespace>_<container>-<id>.log
+	logs, err := filepath.Glob(HostVar("log", "containers", "*-"+id+".log"))
+	if err == nil && len(logs) > 0 {
+		n := strings.TrimSuffix(filepath.Base(logs[0]), "-"+id+".log")
+		parts := strings.Split(n, "_")
+		if len(parts) == 3 {
+			return parts[2]
+		}
+	}
+
+	return ShortContainerID(id)
+}
+
+//readProcessCgroup reads the cgroup path of a process
+func readProcessCgroup(pid int32) string {
+	b, err := ioutil.ReadFile(HostProc(strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return ParseCgroup(string(b))
+}
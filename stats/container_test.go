@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dockerID = "aaaaaaaaaaaabbbbbbbbbbbbccccccccccccddddddddddddeeeeeeeeeeee1234"
+	criID    = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+func TestParseCgroup(t *testing.T) {
+	//cgroup v2
+	assert.Equal(t, "/system.slice/docker-"+dockerID+".scope", ParseCgroup("0::/system.slice/docker-"+dockerID+".scope\n"))
+
+	//cgroup v1 uses memory controller
+	v1 := "12:pids:/docker/other\n11:cpu,cpuacct:/docker/cpu\n4:memory:/docker/" + dockerID + "\n1:name=systemd:/init.scope\n"
+	assert.Equal(t, "/docker/"+dockerID, ParseCgroup(v1))
+
+	assert.Equal(t, "", ParseCgroup(""))
+}
+
+func TestParseContainerInfo(t *testing.T) {
+	ci := ParseContainerInfo("/docker/" + dockerID)
+	assert.Equal(t, dockerID, ci.ContainerID)
+	assert.Equal(t, "", ci.PodUID)
+
+	ci = ParseContainerInfo("/system.slice/docker-" + dockerID + ".scope")
+	assert.Equal(t, dockerID, ci.ContainerID)
+
+	ci = ParseContainerInfo("/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1a2b3c4d_0000_1111_2222_333344445555.slice/cri-containerd-" + criID + ".scope")
+	assert.Equal(t, criID, ci.ContainerID)
+	assert.Equal(t, "1a2b3c4d-0000-1111-2222-333344445555", ci.PodUID)
+
+	ci = ParseContainerInfo("/kubepods/besteffort/pod1a2b3c4d-0000-1111-2222-333344445555/" + criID)
+	assert.Equal(t, criID, ci.ContainerID)
+	assert.Equal(t, "1a2b3c4d-0000-1111-2222-333344445555", ci.PodUID)
+
+	ci = ParseContainerInfo("/user.slice/user-1000.slice/session-2.scope")
+	assert.Equal(t, "/user.slice/user-1000.slice/session-2.scope", ci.Cgroup)
+	assert.Equal(t, "", ci.ContainerID)
+	assert.Equal(t, "", ci.PodUID)
+}
+
+func TestResolveContainerName(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+
+	assert.Equal(t, "billing-api", resolveContainerName(dockerID))
+	assert.Equal(t, "nginx", resolveContainerName(criID))
+	assert.Equal(t, "ffffffffffff", resolveContainerName("ffffffffffff0000000000000000000000000000000000000000000000000000"))
+	assert.Equal(t, "", resolveContainerName(""))
+}
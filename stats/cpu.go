@@ -10,8 +10,9 @@ import (
 )
 
 type CPUStats struct {
-	Total *CPUTimes
-	CPU   []*CPUTimes
+	Total  *CPUTimes
+	CPU    []*CPUTimes
+	source Source
 }
 
 type CPUTimes struct {
@@ -22,15 +23,16 @@ type CPUTimes struct {
 	Steal  signalutils.Timeseries
 }
 
-func NewCPUStats(ctx context.Context, timeseriesMaxSpan time.Duration, sampleFreq float64) *CPUStats {
+func NewCPUStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *CPUStats {
 	logrus.Tracef("CPU Stats: initializing...")
 
-	nrcpu, err := cpu.Counts(true)
+	source = sourceOrDefault(source)
+	nrcpu, err := source.CPUCount()
 	if err != nil {
 		logrus.Warningf("Cannot initilize cpu stats. err=%s", err)
 	}
 
-	ct := &CPUStats{}
+	ct := &CPUStats{source: source}
 	ct.CPU = make([]*CPUTimes, 0)
 	for i := 0; i < nrcpu; i++ {
 		ct.CPU = append(ct.CPU, newCPUTimes(timeseriesMaxSpan))
@@ -63,26 +65,32 @@ func addCPUStats(cpu *cpu.TimesStat, cpuTimes *CPUTimes, cpus float64) {
 }
 
 func (c *CPUStats) cpuStep() error {
-	//overall load
-	cpus, err := cpu.Times(false)
+	cs, err := c.source.CPUTimes()
 	if err != nil {
 		return err
 	}
-	addCPUStats(&cpus[0], c.Total, float64(len(c.CPU)))
+
+	//overall load
+	addCPUStats(&cs.Total, c.Total, float64(len(c.CPU)))
 
 	//load per CPU
-	cpus, err = cpu.Times(true)
-	if err != nil {
-		return err
-	}
 	for i := range c.CPU {
-		cs := c.CPU[i]
-		addCPUStats(&cpus[i], cs, 1)
+		if i >= len(cs.CPUs) {
+			break
+		}
+		addCPUStats(&cs.CPUs[i], c.CPU[i], 1)
 	}
 
 	return nil
 }
 
+//CPUsSnapshot returns the stats of each cpu. The cpus are set when stats are created
+func (c *CPUStats) CPUsSnapshot() []*CPUTimes {
+	cpus := make([]*CPUTimes, len(c.CPU))
+	copy(cpus, c.CPU)
+	return cpus
+}
+
 func (c *CPUStats) CPUCount() (int, error) {
-	return cpu.Counts(true)
+	return c.source.CPUCount()
 }
@@ -13,7 +13,7 @@ func TestCPUWatcherTotal(t *testing.T) {
 	// logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	s := NewCPUStats(ctx, 60*time.Second, 2)
+	s := NewCPUStats(ctx, nil, 60*time.Second, 2)
 	time.Sleep(5 * time.Second)
 	tc, ok := TimeLoadPerc(&s.Total.Idle, 3*time.Second)
 	// fmt.Printf(">>>>> %f\n", tc)
@@ -26,7 +26,7 @@ func TestCPUWatcherPerCPU(t *testing.T) {
 	// logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	s := NewCPUStats(ctx, 60*time.Second, 2)
+	s := NewCPUStats(ctx, nil, 60*time.Second, 2)
 	time.Sleep(5 * time.Second)
 	// tc1, ok := CPUAvgPerc(&cpuStats.CPU[0].Idle, 3*time.Second)
 	tc2, ok := TimeLoadPerc(&s.CPU[0].User, 3*time.Second)
@@ -3,13 +3,14 @@ package stats
 import (
 	"context"
 	"io/ioutil"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/flaviostutz/signalutils"
-	"github.com/shirou/gopsutil/disk"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,6 +20,8 @@ type DiskStats struct {
 	FD                 *FDMetrics
 	timeseriesSize     time.Duration
 	ioRateLoadDuration time.Duration
+	source             Source
+	m                  sync.RWMutex
 }
 
 type FDMetrics struct {
@@ -26,9 +29,20 @@ type FDMetrics struct {
 	MaxFD  int64
 }
 
+const (
+	//DiskClassHDD rotational disk
+	DiskClassHDD = "hdd"
+	//DiskClassSSD non rotational disk
+	DiskClassSSD = "ssd"
+	//DiskClassNVMe nvme attached disk
+	DiskClassNVMe = "nvme"
+)
+
 type DiskMetrics struct {
-	Name           string
-	SerialNumber   string
+	Name         string
+	SerialNumber string
+	//Class hdd, ssd or nvme. Empty if unknown
+	Class          string
 	IoTime         signalutils.Timeseries
 	ReadBytes      signalutils.TimeseriesCounterRate
 	ReadCount      signalutils.TimeseriesCounterRate
@@ -37,6 +51,8 @@ type DiskMetrics struct {
 	WriteCount     signalutils.TimeseriesCounterRate
 	WriteTime      signalutils.Timeseries
 	IopsInProgress signalutils.Timeseries
+	//WeightedIO cumulative time spent doing ios in ms, weighted by the number of ios in progress
+	WeightedIO signalutils.Timeseries
 }
 
 type PartitionMetrics struct {
@@ -48,7 +64,7 @@ type PartitionMetrics struct {
 	InodesFree  signalutils.Timeseries
 }
 
-func NewDiskStats(ctx context.Context, timeseriesSize time.Duration, ioRateLoadDuration time.Duration, sampleFreq float64) *DiskStats {
+func NewDiskStats(ctx context.Context, source Source, timeseriesSize time.Duration, ioRateLoadDuration time.Duration, sampleFreq float64) *DiskStats {
 	logrus.Tracef("Disk Stats: initializing...")
 
 	d := &DiskStats{
@@ -60,6 +76,7 @@ func NewDiskStats(ctx context.Context, timeseriesSize time.Duration, ioRateLoadD
 		},
 		timeseriesSize:     timeseriesSize,
 		ioRateLoadDuration: ioRateLoadDuration,
+		source:             sourceOrDefault(source),
 	}
 
 	signalutils.StartWorker(ctx, "disk", d.diskStep, sampleFreq/2, sampleFreq, true)
@@ -71,16 +88,16 @@ func NewDiskStats(ctx context.Context, timeseriesSize time.Duration, ioRateLoadD
 func (d *DiskStats) diskStep() error {
 
 	//fd stats
-	usedFD, maxFD, err := FDStats()
+	fds, err := d.source.FileDescriptors()
 	if err != nil {
 		logrus.Tracef("FD stats works only on Linux systems. err=%s", err)
 	} else {
-		d.FD.MaxFD = maxFD
-		d.FD.UsedFD.Add(float64(usedFD))
+		d.FD.MaxFD = fds.Max
+		d.FD.UsedFD.Add(float64(fds.Used))
 	}
 
 	//stats per disk
-	ioc, err := disk.IOCounters()
+	ioc, err := d.source.DiskIOCounters()
 	if err != nil {
 		return err
 	}
@@ -92,6 +109,7 @@ func (d *DiskStats) diskStep() error {
 			dm = &DiskMetrics{
 				Name:           name,
 				SerialNumber:   is.SerialNumber,
+				Class:          diskClass(name),
 				IoTime:         signalutils.NewTimeseries(d.timeseriesSize),
 				IopsInProgress: signalutils.NewTimeseries(d.timeseriesSize),
 				ReadBytes:      signalutils.NewTimeseriesCounterRate(d.timeseriesSize),
@@ -100,9 +118,12 @@ func (d *DiskStats) diskStep() error {
 				WriteBytes:     signalutils.NewTimeseriesCounterRate(d.timeseriesSize),
 				WriteCount:     signalutils.NewTimeseriesCounterRate(d.timeseriesSize),
 				WriteTime:      signalutils.NewTimeseries(d.timeseriesSize),
+				WeightedIO:     signalutils.NewTimeseries(d.timeseriesSize),
 			}
 
+			d.m.Lock()
 			d.Disks[name] = dm
+			d.m.Unlock()
 		}
 
 		//add stats to timeseries
@@ -114,34 +135,33 @@ func (d *DiskStats) diskStep() error {
 		dm.WriteBytes.Set(float64(is.WriteBytes))
 		dm.WriteCount.Set(float64(is.WriteCount))
 		dm.WriteTime.Add(float64(is.WriteTime))
+		dm.WeightedIO.Add(float64(is.WeightedIO))
 	}
 
 	//stats per partition
-	partitions, err := disk.Partitions(true)
+	partitions, err := d.source.Partitions()
 	if err != nil {
 		return err
 	}
 
-	for _, p := range partitions {
-		pm, ok := d.Partitions[p.Mountpoint]
+	for _, pu := range partitions {
+		pm, ok := d.Partitions[pu.Path]
 
 		if !ok {
 			pm = &PartitionMetrics{
-				Path:        p.Mountpoint,
-				Fstype:      p.Fstype,
+				Path:        pu.Path,
+				Fstype:      pu.Fstype,
 				Total:       0,
 				Free:        signalutils.NewTimeseries(d.timeseriesSize),
 				InodesTotal: 0,
 				InodesFree:  signalutils.NewTimeseries(d.timeseriesSize),
 			}
-			d.Partitions[p.Mountpoint] = pm
+			d.m.Lock()
+			d.Partitions[pu.Path] = pm
+			d.m.Unlock()
 		}
 
 		//add stats to timeseries
-		pu, err := disk.Usage(p.Mountpoint)
-		if err != nil {
-			return err
-		}
 		pm.Free.Add(float64(pu.Free))
 		pm.Total = pu.Total
 		pm.InodesFree.Add(float64(pu.InodesFree))
@@ -151,6 +171,30 @@ func (d *DiskStats) diskStep() error {
 	return nil
 }
 
+//diskClass detects the class of a disk by its name and /sys/block/<disk>/queue/rotational
+//Partitions have the class of their disk
+func diskClass(name string) string {
+	if strings.HasPrefix(name, "nvme") {
+		return DiskClassNVMe
+	}
+	b, err := ioutil.ReadFile(HostSys("block", name, "queue", "rotational"))
+	if err != nil {
+		//partitions are at /sys/class/block/<disk>/<partition>
+		dir, err := filepath.EvalSymlinks(HostSys("class", "block", name))
+		if err != nil {
+			return ""
+		}
+		b, err = ioutil.ReadFile(filepath.Join(filepath.Dir(dir), "queue", "rotational"))
+		if err != nil {
+			return ""
+		}
+	}
+	if strings.TrimSpace(string(b)) == "1" {
+		return DiskClassHDD
+	}
+	return DiskClassSSD
+}
+
 func (d *DiskStats) TopOpRate(read bool) []*DiskMetrics {
 	da := d.diskArray()
 	sort.Slice(da, func(i, j int) bool {
@@ -208,7 +252,19 @@ func (d *DiskStats) TopIOUtil(read bool) []*DiskMetrics {
 	return da
 }
 
+//DisksSnapshot returns the disks seen so far. It can be called while stats are being collected
+func (d *DiskStats) DisksSnapshot() []*DiskMetrics {
+	return d.diskArray()
+}
+
+//PartitionsSnapshot returns the partitions seen so far. It can be called while stats are being collected
+func (d *DiskStats) PartitionsSnapshot() []*PartitionMetrics {
+	return d.partitionArray()
+}
+
 func (d *DiskStats) diskArray() []*DiskMetrics {
+	d.m.RLock()
+	defer d.m.RUnlock()
 	dms := make([]*DiskMetrics, 0)
 	for _, v := range d.Disks {
 		dms = append(dms, v)
@@ -217,6 +273,8 @@ func (d *DiskStats) diskArray() []*DiskMetrics {
 }
 
 func (d *DiskStats) partitionArray() []*PartitionMetrics {
+	d.m.RLock()
+	defer d.m.RUnlock()
 	dms := make([]*PartitionMetrics, 0)
 	for _, v := range d.Partitions {
 		dms = append(dms, v)
@@ -225,7 +283,7 @@ func (d *DiskStats) partitionArray() []*PartitionMetrics {
 }
 
 func FDStats() (usedFD int64, maxFD int64, err error) {
-	filenrb, err := ioutil.ReadFile("/proc/sys/fs/file-nr")
+	filenrb, err := ioutil.ReadFile(HostProc("sys", "fs", "file-nr"))
 	if err != nil {
 		return -1, -1, err
 	}
@@ -13,7 +13,7 @@ func TestDiskStatsBasic(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ps := NewDiskStats(ctx, 120*time.Second, 2*time.Second, 1)
+	ps := NewDiskStats(ctx, nil, 120*time.Second, 2*time.Second, 1)
 	time.Sleep(4 * time.Second)
 	assert.GreaterOrEqual(t, len(ps.Disks), 1)
 
@@ -36,7 +36,7 @@ func TestDiskTop(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ps := NewDiskStats(ctx, 120*time.Second, 2*time.Second, 1)
+	ps := NewDiskStats(ctx, nil, 120*time.Second, 2*time.Second, 1)
 	time.Sleep(5 * time.Second)
 
 	td := ps.TopByteRate(true)
@@ -63,3 +63,13 @@ func TestDiskTop(t *testing.T) {
 	td = ps.TopIOUtil(false)
 	assert.Greater(t, len(td), 0)
 }
+
+func TestDiskClass(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+	assert.Equal(t, DiskClassHDD, diskClass("sda"))
+	assert.Equal(t, DiskClassHDD, diskClass("sda1"))
+	assert.Equal(t, DiskClassSSD, diskClass("sdb"))
+	assert.Equal(t, DiskClassNVMe, diskClass("nvme0n1p1"))
+	assert.Equal(t, "", diskClass("sdz"))
+}
@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+)
+
+var hostRoot = ""
+
+//hostDirs env vars used by gopsutil to locate system dirs and their default paths
+var hostDirs = map[string]string{
+	"HOST_PROC": "proc",
+	"HOST_SYS":  "sys",
+	"HOST_ETC":  "etc",
+	"HOST_VAR":  "var",
+	"HOST_RUN":  "run",
+	"HOST_DEV":  "dev",
+}
+
+//SetHostRoot makes all collectors read system information from <root>/proc, <root>/sys, <root>/etc
+//instead of /proc, /sys and /etc. Useful when running inside a container with the host root mounted
+//at some dir (ex.: /host). Use "" to read from the current root again
+func SetHostRoot(root string) {
+	hostRoot = root
+	for env, dir := range hostDirs {
+		if root == "" {
+			os.Unsetenv(env)
+			continue
+		}
+		os.Setenv(env, filepath.Join(root, dir))
+	}
+}
+
+//HostRoot returns the root dir set by SetHostRoot
+func HostRoot() string {
+	return hostRoot
+}
+
+//HostProc returns a path inside the host /proc dir
+func HostProc(paths ...string) string {
+	return hostPath("HOST_PROC", "/proc", paths...)
+}
+
+//HostSys returns a path inside the host /sys dir
+func HostSys(paths ...string) string {
+	return hostPath("HOST_SYS", "/sys", paths...)
+}
+
+//HostEtc returns a path inside the host /etc dir
+func HostEtc(paths ...string) string {
+	return hostPath("HOST_ETC", "/etc", paths...)
+}
+
+//HostVar returns a path inside the host /var dir
+func HostVar(paths ...string) string {
+	return hostPath("HOST_VAR", "/var", paths...)
+}
+
+//Hostname returns the host name
+//when a host root is set it is read from the host /etc/hostname and then from
+//the host /proc/sys/kernel/hostname, because the container has its own host name
+func Hostname() (string, error) {
+	if hostRoot == "" {
+		return os.Hostname()
+	}
+	b, err := ioutil.ReadFile(HostEtc("hostname"))
+	if err != nil {
+		b, err = ioutil.ReadFile(HostProc("sys", "kernel", "hostname"))
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func hostPath(env string, def string, paths ...string) string {
+	dir := os.Getenv(env)
+	if dir == "" {
+		dir = def
+	}
+	return filepath.Join(append([]string{dir}, paths...)...)
+}
+
+//hostPartitions lists partitions mounted on host
+//gopsutil uses /proc/self/mountinfo, which shows container mounts, so
+//when a host root is set the mounts of host's init process are used instead
+func hostPartitions() ([]disk.PartitionStat, error) {
+	if hostRoot == "" {
+		return disk.Partitions(true)
+	}
+	f, err := os.Open(HostProc("1", "mounts"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	partitions := make([]disk.PartitionStat, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		partitions = append(partitions, disk.PartitionStat{
+			Device:     fields[0],
+			Mountpoint: fields[1],
+			Fstype:     fields[2],
+			Opts:       fields[3],
+		})
+	}
+	return partitions, scanner.Err()
+}
+
+//hostPartitionUsage gets usage for a partition mounted on host
+func hostPartitionUsage(mountpoint string) (*disk.UsageStat, error) {
+	if hostRoot == "" {
+		return disk.Usage(mountpoint)
+	}
+	us, err := disk.Usage(filepath.Join(hostRoot, mountpoint))
+	if err != nil {
+		return nil, err
+	}
+	us.Path = mountpoint
+	return us, nil
+}
+
+//hostNetIOCounters get NIC counters from host network namespace
+func hostNetIOCounters() ([]net.IOCountersStat, error) {
+	if hostRoot == "" {
+		return net.IOCounters(true)
+	}
+	return net.IOCountersByFile(true, HostProc("1", "net", "dev"))
+}
+
+//hostNetFile returns the path of a /proc/net file of the host network namespace
+func hostNetFile(name string) string {
+	if hostRoot == "" {
+		return HostProc("net", name)
+	}
+	return HostProc("1", "net", name)
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/mem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostRoot(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+
+	assert.Equal(t, "testdata/hostroot/proc/meminfo", HostProc("meminfo"))
+	assert.Equal(t, "testdata/hostroot/sys/block", HostSys("block"))
+	assert.Equal(t, "testdata/hostroot/etc/hostname", HostEtc("hostname"))
+
+	hn, err := Hostname()
+	assert.Nil(t, err)
+	assert.Equal(t, "fixture-host", hn)
+
+	used, max, err := FDStats()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4128), used)
+	assert.Equal(t, int64(9223372036854775807), max)
+
+	vm, err := mem.VirtualMemory()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(8000000*1024), vm.Total)
+
+	parts, err := hostPartitions()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(parts))
+	assert.Equal(t, "/data", parts[2].Mountpoint)
+	assert.Equal(t, "xfs", parts[2].Fstype)
+
+	nics, err := hostNetIOCounters()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(nics))
+	assert.Equal(t, "eth0", nics[1].Name)
+	assert.Equal(t, uint64(5000000), nics[1].BytesRecv)
+}
+
+func TestHostRootReset(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	SetHostRoot("")
+	assert.Equal(t, "/proc/meminfo", HostProc("meminfo"))
+	assert.Equal(t, "", HostRoot())
+}
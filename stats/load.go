@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+//LoadStats load average, runnable tasks and scheduler activity
+type LoadStats struct {
+	Load1  signalutils.Timeseries
+	Load5  signalutils.Timeseries
+	Load15 signalutils.Timeseries
+	//ProcsRunning tasks running or waiting for a cpu
+	ProcsRunning signalutils.Timeseries
+	//ProcsBlocked tasks blocked waiting for io
+	ProcsBlocked signalutils.Timeseries
+	CtxSwitches  signalutils.TimeseriesCounterRate
+	Interrupts   signalutils.TimeseriesCounterRate
+	source       Source
+}
+
+func NewLoadStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *LoadStats {
+	logrus.Tracef("Load Stats: initializing...")
+	l := &LoadStats{
+		Load1:        signalutils.NewTimeseries(timeseriesMaxSpan),
+		Load5:        signalutils.NewTimeseries(timeseriesMaxSpan),
+		Load15:       signalutils.NewTimeseries(timeseriesMaxSpan),
+		ProcsRunning: signalutils.NewTimeseries(timeseriesMaxSpan),
+		ProcsBlocked: signalutils.NewTimeseries(timeseriesMaxSpan),
+		CtxSwitches:  signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		Interrupts:   signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		source:       sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "load", l.loadStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Load Stats: running")
+	return l
+}
+
+func (l *LoadStats) loadStep() error {
+	s, err := l.source.Load()
+	if err != nil {
+		return err
+	}
+	l.Load1.Add(s.Load1)
+	l.Load5.Add(s.Load5)
+	l.Load15.Add(s.Load15)
+	l.ProcsRunning.Add(float64(s.ProcsRunning))
+	l.ProcsBlocked.Add(float64(s.ProcsBlocked))
+	setCounter(&l.CtxSwitches, float64(s.CtxSwitches))
+	setCounter(&l.Interrupts, float64(s.Interrupts))
+	return nil
+}
+
+//readLoad reads load averages from loadavgFile (/proc/loadavg) and
+//ctxt, intr, procs_running and procs_blocked from statFile (/proc/stat)
+func readLoad(loadavgFile string, statFile string) (LoadSample, error) {
+	ls := LoadSample{}
+
+	b, err := ioutil.ReadFile(loadavgFile)
+	if err != nil {
+		return ls, err
+	}
+	//ex.: "0.52 0.58 0.59 2/1023 12345"
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return ls, fmt.Errorf("Invalid load average %s", string(b))
+	}
+	ls.Load1, err = strconv.ParseFloat(fields[0], 64)
+	if err == nil {
+		ls.Load5, err = strconv.ParseFloat(fields[1], 64)
+	}
+	if err == nil {
+		ls.Load15, err = strconv.ParseFloat(fields[2], 64)
+	}
+	if err != nil {
+		return ls, fmt.Errorf("Invalid load average %s. err=%s", string(b), err)
+	}
+
+	f, err := os.Open(statFile)
+	if err != nil {
+		return ls, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	//intr lines have a counter for each irq, so they may be very long
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var v *uint64
+		switch fields[0] {
+		case "ctxt":
+			v = &ls.CtxSwitches
+		case "intr":
+			//the first value is the total
+			v = &ls.Interrupts
+		case "procs_running":
+			v = &ls.ProcsRunning
+		case "procs_blocked":
+			v = &ls.ProcsBlocked
+		default:
+			continue
+		}
+		*v, err = strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return ls, fmt.Errorf("Invalid %s value in %s. err=%s", fields[0], statFile, err)
+		}
+	}
+	return ls, scanner.Err()
+}
@@ -0,0 +1,24 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLoad(t *testing.T) {
+	l, err := readLoad("testdata/hostroot/proc/loadavg", "testdata/hostroot/proc/stat")
+	assert.Nil(t, err)
+	assert.Equal(t, 12.52, l.Load1)
+	assert.Equal(t, 8.58, l.Load5)
+	assert.Equal(t, 4.59, l.Load15)
+	assert.Equal(t, uint64(34), l.ProcsRunning)
+	assert.Equal(t, uint64(2), l.ProcsBlocked)
+	assert.Equal(t, uint64(5117920), l.CtxSwitches)
+	assert.Equal(t, uint64(1462898), l.Interrupts)
+
+	_, err = readLoad("testdata/hostroot/proc/none", "testdata/hostroot/proc/stat")
+	assert.NotNil(t, err)
+	_, err = readLoad("testdata/hostroot/proc/loadavg", "testdata/hostroot/proc/none")
+	assert.NotNil(t, err)
+}
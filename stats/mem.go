@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/flaviostutz/signalutils"
-	"github.com/shirou/gopsutil/mem"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,12 +18,15 @@ type MemStats struct {
 	SwapTotal uint64
 	SwapUsed  signalutils.Timeseries
 	SwapFree  signalutils.Timeseries
+	//OOMKill cumulative number of processes killed by the OOM killer
+	OOMKill signalutils.Timeseries
+	source  Source
 }
 
-func NewMemStats(ctx context.Context, timeseriesMaxSpan time.Duration, sampleFreq float64) *MemStats {
+func NewMemStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *MemStats {
 	logrus.Tracef("Mem Stats: initializing...")
 
-	mt := &MemStats{}
+	mt := &MemStats{source: sourceOrDefault(source)}
 	mt.Total = 0.0
 	mt.Available = signalutils.NewTimeseries(timeseriesMaxSpan)
 	mt.Used = signalutils.NewTimeseries(timeseriesMaxSpan)
@@ -34,6 +36,7 @@ func NewMemStats(ctx context.Context, timeseriesMaxSpan time.Duration, sampleFre
 	mt.SwapTotal = 0.0
 	mt.SwapUsed = signalutils.NewTimeseries(timeseriesMaxSpan)
 	mt.SwapFree = signalutils.NewTimeseries(timeseriesMaxSpan)
+	mt.OOMKill = signalutils.NewTimeseries(timeseriesMaxSpan)
 
 	signalutils.StartWorker(ctx, "mem", mt.memStep, sampleFreq/2, sampleFreq, true)
 	logrus.Debugf("Mem Stats: running")
@@ -43,15 +46,12 @@ func NewMemStats(ctx context.Context, timeseriesMaxSpan time.Duration, sampleFre
 
 func (m *MemStats) memStep() error {
 
-	ms, err := mem.VirtualMemory()
+	sample, err := m.source.Memory()
 	if err != nil {
-		logrus.Warningf("Cannot initilize mem stats. err=%s", err)
-	}
-
-	ss, err := mem.SwapMemory()
-	if err != nil {
-		logrus.Warningf("Cannot initilize swap stats. err=%s", err)
+		return err
 	}
+	ms := sample.Memory
+	ss := sample.Swap
 
 	m.Total = ms.Total
 	m.Used.Add(float64(ms.Used))
@@ -62,6 +62,7 @@ func (m *MemStats) memStep() error {
 	m.SwapFree.Add(float64(ss.Free))
 	m.SwapIn.Set(float64(ss.Sin))
 	m.SwapOut.Set(float64(ss.Sout))
+	m.OOMKill.Add(float64(sample.OOMKill))
 
 	return nil
 }
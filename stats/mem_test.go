@@ -12,7 +12,7 @@ func TestMemBasic(t *testing.T) {
 	// logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	s := NewMemStats(ctx, 60*time.Second, 2)
+	s := NewMemStats(ctx, nil, 60*time.Second, 2)
 	time.Sleep(5 * time.Second)
 
 	tot := s.Total
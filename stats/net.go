@@ -2,11 +2,14 @@ package stats
 
 import (
 	"context"
+	"io/ioutil"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/flaviostutz/signalutils"
-	"github.com/shirou/gopsutil/net"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,6 +17,9 @@ type NetStats struct {
 	NICs               map[string]*NICMetrics
 	timeseriesSize     time.Duration
 	ioRateLoadDuration time.Duration
+	lastLinkCheck      time.Time
+	source             Source
+	m                  sync.RWMutex
 }
 
 type NICMetrics struct {
@@ -24,15 +30,20 @@ type NICMetrics struct {
 	PacketsSent signalutils.TimeseriesCounterRate
 	ErrIn       signalutils.TimeseriesCounterRate
 	ErrOut      signalutils.TimeseriesCounterRate
+	//LinkSpeed negotiated link speed in bytes per second. 0 if unknown (ex.: virtual interfaces)
+	LinkSpeed uint64
+	//Duplex full, half or empty if unknown
+	Duplex string
 }
 
-func NewNetStats(ctx context.Context, timeseriesSize time.Duration, ioRateLoadDuration time.Duration, sampleFreq float64) *NetStats {
+func NewNetStats(ctx context.Context, source Source, timeseriesSize time.Duration, ioRateLoadDuration time.Duration, sampleFreq float64) *NetStats {
 	logrus.Tracef("Net Stats: initializing...")
 
 	d := &NetStats{
 		NICs:               make(map[string]*NICMetrics),
 		timeseriesSize:     timeseriesSize,
 		ioRateLoadDuration: ioRateLoadDuration,
+		source:             sourceOrDefault(source),
 	}
 
 	signalutils.StartWorker(ctx, "net", d.netStep, sampleFreq/2, sampleFreq, true)
@@ -44,11 +55,17 @@ func NewNetStats(ctx context.Context, timeseriesSize time.Duration, ioRateLoadDu
 func (d *NetStats) netStep() error {
 
 	//stats per nic
-	ioc, err := net.IOCounters(true)
+	ioc, err := d.source.NetIOCounters()
 	if err != nil {
 		return err
 	}
 
+	//link speed may be renegotiated
+	checkLinks := time.Now().Sub(d.lastLinkCheck) > 1*time.Minute
+	if checkLinks {
+		d.lastLinkCheck = time.Now()
+	}
+
 	for _, is := range ioc {
 		nm, ok := d.NICs[is.Name]
 
@@ -62,7 +79,12 @@ func (d *NetStats) netStep() error {
 				ErrIn:       signalutils.NewTimeseriesCounterRate(d.timeseriesSize),
 				ErrOut:      signalutils.NewTimeseriesCounterRate(d.timeseriesSize),
 			}
+			d.m.Lock()
 			d.NICs[is.Name] = nm
+			d.m.Unlock()
+			nm.LinkSpeed, nm.Duplex = nicLink(is.Name)
+		} else if checkLinks {
+			nm.LinkSpeed, nm.Duplex = nicLink(is.Name)
 		}
 
 		//add stats to timeseries
@@ -77,6 +99,28 @@ func (d *NetStats) netStep() error {
 	return nil
 }
 
+//nicLink reads link speed (bytes per second) and duplex from /sys/class/net/<nic>
+//Virtual interfaces and interfaces that are down have no speed
+func nicLink(name string) (speed uint64, duplex string) {
+	b, err := ioutil.ReadFile(HostSys("class", "net", name, "speed"))
+	if err != nil {
+		return 0, ""
+	}
+	//speed is in Mbit/s. -1 if unknown
+	mbps, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil || mbps <= 0 {
+		return 0, ""
+	}
+	b, err = ioutil.ReadFile(HostSys("class", "net", name, "duplex"))
+	if err == nil {
+		duplex = strings.TrimSpace(string(b))
+		if duplex != "full" && duplex != "half" {
+			duplex = ""
+		}
+	}
+	return uint64(mbps) * 1000000 / 8, duplex
+}
+
 func (d *NetStats) TopPacketRate(recv bool) []*NICMetrics {
 	da := d.nicArray()
 	sort.Slice(da, func(i, j int) bool {
@@ -134,7 +178,14 @@ func (d *NetStats) TopErrorsRate(in bool) []*NICMetrics {
 	return da
 }
 
+//NICsSnapshot returns the network interfaces seen so far. It can be called while stats are being collected
+func (d *NetStats) NICsSnapshot() []*NICMetrics {
+	return d.nicArray()
+}
+
 func (d *NetStats) nicArray() []*NICMetrics {
+	d.m.RLock()
+	defer d.m.RUnlock()
 	dms := make([]*NICMetrics, 0)
 	for _, v := range d.NICs {
 		dms = append(dms, v)
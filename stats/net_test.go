@@ -2,9 +2,11 @@ package stats
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/shirou/gopsutil/net"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,7 +15,7 @@ func TestNetStatsBasic(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ps := NewNetStats(ctx, 120*time.Second, 2*time.Second, 1)
+	ps := NewNetStats(ctx, nil, 120*time.Second, 2*time.Second, 1)
 	time.Sleep(4 * time.Second)
 	assert.GreaterOrEqual(t, len(ps.NICs), 1)
 
@@ -30,7 +32,7 @@ func TestNetTop(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ps := NewNetStats(ctx, 120*time.Second, 2*time.Second, 1)
+	ps := NewNetStats(ctx, nil, 120*time.Second, 2*time.Second, 1)
 	time.Sleep(5 * time.Second)
 
 	td := ps.TopByteRate(true)
@@ -60,3 +62,45 @@ func TestNetTop(t *testing.T) {
 	td = ps.TopErrorsRate(false)
 	assert.Greater(t, len(td), 0)
 }
+
+func TestNICLink(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+	speed, duplex := nicLink("eth0")
+	assert.Equal(t, uint64(125000000), speed)
+	assert.Equal(t, "full", duplex)
+	speed, duplex = nicLink("eth2")
+	assert.Equal(t, uint64(12500000), speed)
+	assert.Equal(t, "half", duplex)
+	//link down
+	speed, duplex = nicLink("eth1")
+	assert.Equal(t, uint64(0), speed)
+	assert.Equal(t, "", duplex)
+	//virtual interface
+	speed, _ = nicLink("veth0")
+	assert.Equal(t, uint64(0), speed)
+}
+
+func TestNICsSnapshot(t *testing.T) {
+	//a new virtual interface appears in each sample
+	tl := Timeline{}
+	for i := 0; i < 20; i++ {
+		nics := make([]net.IOCountersStat, 0)
+		for n := 0; n <= i; n++ {
+			nics = append(nics, net.IOCountersStat{Name: fmt.Sprintf("veth%d", n)})
+		}
+		tl.NICs = append(tl.NICs, nics)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ns := NewNetStats(ctx, NewScriptedSource(tl), 120*time.Second, 2*time.Second, 20)
+
+	//reading while interfaces are added must not fail
+	last := 0
+	for start := time.Now(); time.Since(start) < 1500*time.Millisecond; {
+		nics := ns.NICsSnapshot()
+		assert.GreaterOrEqual(t, len(nics), last)
+		last = len(nics)
+	}
+	assert.Equal(t, 20, last)
+}
@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/shirou/gopsutil/net"
+	"github.com/sirupsen/logrus"
+)
+
+//NetSNMPStats tcp and udp stack counters of the kernel
+type NetSNMPStats struct {
+	TCPInSegs          signalutils.TimeseriesCounterRate
+	TCPOutSegs         signalutils.TimeseriesCounterRate
+	TCPRetransSegs     signalutils.TimeseriesCounterRate
+	TCPActiveOpens     signalutils.TimeseriesCounterRate
+	TCPPassiveOpens    signalutils.TimeseriesCounterRate
+	TCPAttemptFails    signalutils.TimeseriesCounterRate
+	TCPEstabResets     signalutils.TimeseriesCounterRate
+	TCPInErrs          signalutils.TimeseriesCounterRate
+	TCPOutRsts         signalutils.TimeseriesCounterRate
+	TCPListenOverflows signalutils.TimeseriesCounterRate
+	TCPListenDrops     signalutils.TimeseriesCounterRate
+	TCPTimeouts        signalutils.TimeseriesCounterRate
+	UDPInDatagrams     signalutils.TimeseriesCounterRate
+	UDPOutDatagrams    signalutils.TimeseriesCounterRate
+	UDPInErrors        signalutils.TimeseriesCounterRate
+	UDPRcvbufErrors    signalutils.TimeseriesCounterRate
+	UDPSndbufErrors    signalutils.TimeseriesCounterRate
+	source             Source
+}
+
+func NewNetSNMPStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *NetSNMPStats {
+	logrus.Tracef("Net SNMP Stats: initializing...")
+	n := &NetSNMPStats{
+		TCPInSegs:          signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPOutSegs:         signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPRetransSegs:     signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPActiveOpens:     signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPPassiveOpens:    signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPAttemptFails:    signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPEstabResets:     signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPInErrs:          signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPOutRsts:         signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPListenOverflows: signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPListenDrops:     signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		TCPTimeouts:        signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		UDPInDatagrams:     signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		UDPOutDatagrams:    signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		UDPInErrors:        signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		UDPRcvbufErrors:    signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		UDPSndbufErrors:    signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		source:             sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "netsnmp", n.netSNMPStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Net SNMP Stats: running")
+	return n
+}
+
+func (n *NetSNMPStats) netSNMPStep() error {
+	s, err := n.source.NetSNMP()
+	if err != nil {
+		return err
+	}
+	setCounter(&n.TCPInSegs, float64(s.TCPInSegs))
+	setCounter(&n.TCPOutSegs, float64(s.TCPOutSegs))
+	setCounter(&n.TCPRetransSegs, float64(s.TCPRetransSegs))
+	setCounter(&n.TCPActiveOpens, float64(s.TCPActiveOpens))
+	setCounter(&n.TCPPassiveOpens, float64(s.TCPPassiveOpens))
+	setCounter(&n.TCPAttemptFails, float64(s.TCPAttemptFails))
+	setCounter(&n.TCPEstabResets, float64(s.TCPEstabResets))
+	setCounter(&n.TCPInErrs, float64(s.TCPInErrs))
+	setCounter(&n.TCPOutRsts, float64(s.TCPOutRsts))
+	setCounter(&n.TCPListenOverflows, float64(s.TCPListenOverflows))
+	setCounter(&n.TCPListenDrops, float64(s.TCPListenDrops))
+	setCounter(&n.TCPTimeouts, float64(s.TCPTimeouts))
+	setCounter(&n.UDPInDatagrams, float64(s.UDPInDatagrams))
+	setCounter(&n.UDPOutDatagrams, float64(s.UDPOutDatagrams))
+	setCounter(&n.UDPInErrors, float64(s.UDPInErrors))
+	setCounter(&n.UDPRcvbufErrors, float64(s.UDPRcvbufErrors))
+	setCounter(&n.UDPSndbufErrors, float64(s.UDPSndbufErrors))
+	return nil
+}
+
+//readNetSNMP reads /proc/net/snmp and /proc/net/netstat. TcpExt counters are zero if netstat can't be read
+func readNetSNMP(snmpFile string, netstatFile string) (NetSNMPSample, error) {
+	snmp, err := readProtoCounters(snmpFile)
+	if err != nil {
+		return NetSNMPSample{}, fmt.Errorf("Cannot read net snmp counters. err=%s", err)
+	}
+	netstat, err := readProtoCounters(netstatFile)
+	if err != nil {
+		logrus.Tracef("Cannot read net netstat counters. err=%s", err)
+	}
+	tcp := snmp["Tcp"]
+	udp := snmp["Udp"]
+	tcpExt := netstat["TcpExt"]
+	return NetSNMPSample{
+		TCPInSegs:          tcp["InSegs"],
+		TCPOutSegs:         tcp["OutSegs"],
+		TCPRetransSegs:     tcp["RetransSegs"],
+		TCPActiveOpens:     tcp["ActiveOpens"],
+		TCPPassiveOpens:    tcp["PassiveOpens"],
+		TCPAttemptFails:    tcp["AttemptFails"],
+		TCPEstabResets:     tcp["EstabResets"],
+		TCPInErrs:          tcp["InErrs"],
+		TCPOutRsts:         tcp["OutRsts"],
+		TCPListenOverflows: tcpExt["ListenOverflows"],
+		TCPListenDrops:     tcpExt["ListenDrops"],
+		TCPTimeouts:        tcpExt["TCPTimeouts"],
+		UDPInDatagrams:     udp["InDatagrams"],
+		UDPOutDatagrams:    udp["OutDatagrams"],
+		UDPInErrors:        udp["InErrors"],
+		UDPRcvbufErrors:    udp["RcvbufErrors"],
+		UDPSndbufErrors:    udp["SndbufErrors"],
+	}, nil
+}
+
+//readProtoCounters reads files with pairs of lines like "Tcp: RtoAlgorithm RtoMin..." and "Tcp: 1 200..."
+//Returns counters by protocol and counter name
+func readProtoCounters(file string) (map[string]map[string]uint64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	counters := make(map[string]map[string]uint64)
+	var header []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if header == nil || header[0] != fields[0] {
+			header = fields
+			continue
+		}
+		proto := strings.TrimSuffix(fields[0], ":")
+		pc := make(map[string]uint64)
+		for i := 1; i < len(fields) && i < len(header); i++ {
+			//some counters (ex.: Tcp MaxConn) may be negative
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				continue
+			}
+			pc[header[i]] = v
+		}
+		counters[proto] = pc
+		header = nil
+	}
+	return counters, scanner.Err()
+}
+
+//listens sockets a process is listening on, from its connections. ex.: tcp:8080, udp:53
+func listens(conns []net.ConnectionStat) []string {
+	seen := make(map[string]bool)
+	ls := make([]string, 0)
+	for _, c := range conns {
+		l := ""
+		if c.Type == 1 && c.Status == "LISTEN" {
+			l = fmt.Sprintf("tcp:%d", c.Laddr.Port)
+		} else if c.Type == 2 && c.Raddr.Port == 0 {
+			l = fmt.Sprintf("udp:%d", c.Laddr.Port)
+		}
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		ls = append(ls, l)
+	}
+	sort.Strings(ls)
+	return ls
+}
@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadNetSNMP(t *testing.T) {
+	s, err := readNetSNMP("testdata/hostroot/proc/net/snmp", "testdata/hostroot/proc/net/netstat")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2791022), s.TCPInSegs)
+	assert.Equal(t, uint64(2812345), s.TCPOutSegs)
+	assert.Equal(t, uint64(1502), s.TCPRetransSegs)
+	assert.Equal(t, uint64(405), s.TCPEstabResets)
+	assert.Equal(t, uint64(1201), s.TCPOutRsts)
+	assert.Equal(t, uint64(33), s.TCPListenOverflows)
+	assert.Equal(t, uint64(35), s.TCPListenDrops)
+	assert.Equal(t, uint64(219), s.TCPTimeouts)
+	assert.Equal(t, uint64(40211), s.UDPInDatagrams)
+	assert.Equal(t, uint64(10), s.UDPRcvbufErrors)
+	assert.Equal(t, uint64(2), s.UDPSndbufErrors)
+
+	//netstat is optional
+	s, err = readNetSNMP("testdata/hostroot/proc/net/snmp", "testdata/hostroot/proc/net/none")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1502), s.TCPRetransSegs)
+	assert.Equal(t, uint64(0), s.TCPListenDrops)
+
+	_, err = readNetSNMP("testdata/hostroot/proc/net/none", "testdata/hostroot/proc/net/netstat")
+	assert.NotNil(t, err)
+}
+
+func TestListens(t *testing.T) {
+	conns := []net.ConnectionStat{
+		{Type: 1, Status: "LISTEN", Laddr: net.Addr{IP: "0.0.0.0", Port: 8080}},
+		{Type: 1, Status: "LISTEN", Laddr: net.Addr{IP: "::", Port: 8080}},
+		{Type: 1, Status: "ESTABLISHED", Laddr: net.Addr{IP: "10.0.0.1", Port: 8080}, Raddr: net.Addr{IP: "10.0.0.2", Port: 51000}},
+		{Type: 2, Status: "NONE", Laddr: net.Addr{IP: "0.0.0.0", Port: 53}},
+		{Type: 2, Status: "NONE", Laddr: net.Addr{IP: "10.0.0.1", Port: 40000}, Raddr: net.Addr{IP: "10.0.0.3", Port: 53}},
+	}
+	assert.Equal(t, []string{"tcp:8080", "udp:53"}, listens(conns))
+}
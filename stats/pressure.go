@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+//PressureStats pressure stall information (PSI) for cpu, memory and io
+type PressureStats struct {
+	//Supported false when the kernel doesn't provide pressure stall information or before the first sample
+	Supported bool
+	CPU       *PressureMetrics
+	Memory    *PressureMetrics
+	IO        *PressureMetrics
+	source    Source
+}
+
+//PressureMetrics share of time (0-1) in which tasks were stalled waiting for a resource
+type PressureMetrics struct {
+	SomeAvg10 signalutils.Timeseries
+	SomeAvg60 signalutils.Timeseries
+	FullAvg10 signalutils.Timeseries
+	FullAvg60 signalutils.Timeseries
+	//SomeTotal total stall time in seconds
+	SomeTotal signalutils.TimeseriesCounterRate
+	//FullTotal total stall time of all non idle tasks in seconds
+	FullTotal signalutils.TimeseriesCounterRate
+}
+
+func NewPressureStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *PressureStats {
+	logrus.Tracef("Pressure Stats: initializing...")
+	p := &PressureStats{
+		CPU:    newPressureMetrics(timeseriesMaxSpan),
+		Memory: newPressureMetrics(timeseriesMaxSpan),
+		IO:     newPressureMetrics(timeseriesMaxSpan),
+		source: sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "pressure", p.pressureStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Pressure Stats: running")
+	return p
+}
+
+func newPressureMetrics(timeseriesMaxSpan time.Duration) *PressureMetrics {
+	return &PressureMetrics{
+		SomeAvg10: signalutils.NewTimeseries(timeseriesMaxSpan),
+		SomeAvg60: signalutils.NewTimeseries(timeseriesMaxSpan),
+		FullAvg10: signalutils.NewTimeseries(timeseriesMaxSpan),
+		FullAvg60: signalutils.NewTimeseries(timeseriesMaxSpan),
+		SomeTotal: signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+		FullTotal: signalutils.NewTimeseriesCounterRate(timeseriesMaxSpan),
+	}
+}
+
+func (p *PressureStats) pressureStep() error {
+	sample, err := p.source.Pressure()
+	if err != nil {
+		return err
+	}
+	p.Supported = sample.Supported
+	if !sample.Supported {
+		return nil
+	}
+	addPressureStats(sample.CPU, p.CPU)
+	addPressureStats(sample.Memory, p.Memory)
+	addPressureStats(sample.IO, p.IO)
+	return nil
+}
+
+func addPressureStats(s PressureStat, pm *PressureMetrics) {
+	pm.SomeAvg10.Add(s.Some.Avg10 / 100)
+	pm.SomeAvg60.Add(s.Some.Avg60 / 100)
+	pm.FullAvg10.Add(s.Full.Avg10 / 100)
+	pm.FullAvg60.Add(s.Full.Avg60 / 100)
+	setCounter(&pm.SomeTotal, float64(s.Some.Total)/1000000)
+	setCounter(&pm.FullTotal, float64(s.Full.Total)/1000000)
+}
+
+//readPressure reads cpu, memory and io files from the pressure dir (/proc/pressure)
+//Returns an unsupported sample if the files don't exist or can't be read (ex.: kernel booted with psi=0)
+func readPressure(dir string) (PressureSample, error) {
+	ps := PressureSample{Supported: true}
+	var err error
+	ps.CPU, err = readPressureFile(filepath.Join(dir, "cpu"))
+	if err == nil {
+		ps.Memory, err = readPressureFile(filepath.Join(dir, "memory"))
+	}
+	if err == nil {
+		ps.IO, err = readPressureFile(filepath.Join(dir, "io"))
+	}
+	if err != nil {
+		logrus.Tracef("Pressure stall information not available at %s. err=%s", dir, err)
+		return PressureSample{}, nil
+	}
+	return ps, nil
+}
+
+//readPressureFile parses lines like "some avg10=1.53 avg60=0.87 avg300=0.72 total=1271522"
+//The "full" line may not be present for cpu on older kernels
+func readPressureFile(file string) (PressureStat, error) {
+	ps := PressureStat{}
+	f, err := os.Open(file)
+	if err != nil {
+		return ps, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		pl := PressureLine{}
+		for _, kv := range fields[1:] {
+			p := strings.SplitN(kv, "=", 2)
+			if len(p) != 2 {
+				continue
+			}
+			switch p[0] {
+			case "avg10":
+				pl.Avg10, err = strconv.ParseFloat(p[1], 64)
+			case "avg60":
+				pl.Avg60, err = strconv.ParseFloat(p[1], 64)
+			case "avg300":
+				pl.Avg300, err = strconv.ParseFloat(p[1], 64)
+			case "total":
+				pl.Total, err = strconv.ParseUint(p[1], 10, 64)
+			}
+			if err != nil {
+				return ps, fmt.Errorf("Invalid pressure value %s in %s. err=%s", kv, file, err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			ps.Some = pl
+		case "full":
+			ps.Full = pl
+		}
+	}
+	return ps, scanner.Err()
+}
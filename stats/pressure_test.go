@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPressure(t *testing.T) {
+	ps, err := readPressure("testdata/hostroot/proc/pressure")
+	assert.Nil(t, err)
+	assert.True(t, ps.Supported)
+	assert.Equal(t, 25.5, ps.CPU.Some.Avg10)
+	assert.Equal(t, 10.0, ps.CPU.Some.Avg60)
+	assert.Equal(t, uint64(1500000), ps.CPU.Some.Total)
+	//no full line for cpu on older kernels
+	assert.Equal(t, 0.0, ps.CPU.Full.Avg10)
+	assert.Equal(t, 2.5, ps.Memory.Full.Avg10)
+	assert.Equal(t, uint64(100000), ps.Memory.Full.Total)
+	assert.Equal(t, 0.0, ps.IO.Some.Avg60)
+
+	ps, err = readPressure("testdata/hostroot/proc/nopressure")
+	assert.Nil(t, err)
+	assert.False(t, ps.Supported)
+}
+
+func TestScriptedPressureStats(t *testing.T) {
+	tl := Timeline{}
+	for i := 0; i < 50; i++ {
+		tl.Pressure = append(tl.Pressure, PressureSample{
+			Supported: true,
+			IO: PressureStat{
+				Some: PressureLine{Avg10: 40, Avg60: 20, Total: uint64(i) * 40000},
+				Full: PressureLine{Avg10: 10, Avg60: 5, Total: uint64(i) * 10000},
+			},
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewPressureStats(ctx, NewScriptedSource(tl), 60*time.Second, 10)
+
+	time.Sleep(2 * time.Second)
+	assert.True(t, s.Supported)
+	l, ok := s.IO.SomeAvg10.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 0.4, l.Value)
+	l, _ = s.IO.FullAvg60.Last()
+	assert.Equal(t, 0.05, l.Value)
+	//40ms stalled for each 100ms sample
+	rate, ok := s.IO.SomeTotal.Rate(1 * time.Second)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.4, rate, 0.05)
+
+	//kernels without psi
+	s = NewPressureStats(ctx, NewScriptedSource(Timeline{}), 60*time.Second, 10)
+	time.Sleep(500 * time.Millisecond)
+	assert.False(t, s.Supported)
+}
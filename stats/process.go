@@ -3,21 +3,31 @@ package stats
 import (
 	"context"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/flaviostutz/signalutils"
 	"github.com/shirou/gopsutil/net"
-	"github.com/shirou/gopsutil/process"
 	"github.com/sirupsen/logrus"
 )
 
 type ProcessStats struct {
-	Processes          map[int32]*ProcessMetrics
+	Processes map[int32]*ProcessMetrics
+	//UserThreads number of threads by real user id in the last sample
+	UserThreads map[int32]int
+	//Commands restarts by process command line
+	Commands           map[string]*CommandRestarts
+	lastPids           map[int32]bool
+	restartsMaxSpan    time.Duration
+	samplePeriod       time.Duration
 	timeseriesMaxSpan  time.Duration
 	ioLoadRateTimeSpan time.Duration
 	memAvgTimeSpan     time.Duration
 	cpuLoadTimeSpan    time.Duration
 	lastCleanupTime    time.Time
+	source             Source
+	containerNames     map[string]string
+	m                  sync.RWMutex
 }
 
 type NetIOCounters struct {
@@ -41,6 +51,10 @@ type ProcessMetrics struct {
 	Pid                int32
 	Name               string
 	Cmdline            string
+	Cgroup             string
+	ContainerID        string
+	ContainerName      string
+	PodUID             string
 	LastSeen           time.Time
 	CPUTimes           *CPUTimes
 	Connections        signalutils.Timeseries
@@ -52,16 +66,44 @@ type ProcessMetrics struct {
 	MemorySwap         signalutils.Timeseries
 	FD                 signalutils.Timeseries
 	OpenFiles          signalutils.Timeseries
+	//Listens sockets the process is listening on. ex.: tcp:8080, udp:53
+	Listens []string
+	//UID real user id. -1 if unknown
+	UID                    int32
+	NumThreads             signalutils.Timeseries
+	Limits                 ProcessLimits
+	VoluntaryCtxSwitches   signalutils.TimeseriesCounterRate
+	InvoluntaryCtxSwitches signalutils.TimeseriesCounterRate
+	//PPid parent pid. 0 if unknown
+	PPid int32
+	//State current process state. See ProcessState* constants
+	State string
+	//StateChanges state transitions in the timeseries span. The last one is the current state
+	StateChanges []ProcessStateChange
+	//Wchan kernel function the process is blocked in. Only set while in D state
+	Wchan string
+	//Cwd current dir of the process. Only set while in D state
+	Cwd string
 }
 
-func NewProcessStats(ctx context.Context, timeseriesMaxSpan time.Duration, ioLoadRateTimeSpan time.Duration, cpuLoadTimeSpan time.Duration, memAvgTimeSpan time.Duration, sampleFreq float64) *ProcessStats {
+//NewProcessStats starts collecting stats of running processes
+//Restarts of processes are kept for restartSpan or timeseriesMaxSpan, whichever is longer
+func NewProcessStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, ioLoadRateTimeSpan time.Duration, cpuLoadTimeSpan time.Duration, memAvgTimeSpan time.Duration, restartSpan time.Duration, sampleFreq float64) *ProcessStats {
 	logrus.Tracef("Process Stats: initializing...")
 	ps := &ProcessStats{
 		Processes:          make(map[int32]*ProcessMetrics),
+		Commands:           make(map[string]*CommandRestarts),
 		ioLoadRateTimeSpan: ioLoadRateTimeSpan,
 		cpuLoadTimeSpan:    cpuLoadTimeSpan,
 		timeseriesMaxSpan:  timeseriesMaxSpan,
 		memAvgTimeSpan:     memAvgTimeSpan,
+		restartsMaxSpan:    timeseriesMaxSpan,
+		samplePeriod:       time.Duration(float64(time.Second) / sampleFreq),
+		source:             sourceOrDefault(source),
+		containerNames:     make(map[string]string),
+	}
+	if restartSpan > ps.restartsMaxSpan {
+		ps.restartsMaxSpan = restartSpan
 	}
 	signalutils.StartWorker(ctx, "process", ps.processStep, sampleFreq/2, sampleFreq, true)
 	logrus.Debugf("Process Stats: running")
@@ -78,33 +120,31 @@ func (ps *ProcessStats) processStep() error {
 				removePids = append(removePids, pid)
 			}
 		}
+		ps.m.Lock()
 		for _, pi := range removePids {
 			delete(ps.Processes, pi)
 		}
+		ps.m.Unlock()
 		ps.lastCleanupTime = time.Now()
 	}
 
 	//stats per process
-	processes, err := process.Processes()
+	processes, err := ps.source.Processes()
 	if err != nil {
 		return err
 	}
 
-	for _, p := range processes {
+	userThreads := make(map[int32]int)
+	for i := range processes {
+		p := &processes[i]
 		proc, ok := ps.Processes[p.Pid]
 		if !ok {
 			//initialize process counter
 			proc = &ProcessMetrics{}
 
 			proc.Pid = p.Pid
-			proc.Name, err = p.Name()
-			if err != nil {
-				return err
-			}
-			proc.Cmdline, err = p.Cmdline()
-			if err != nil {
-				return err
-			}
+			proc.Name = p.Name
+			proc.Cmdline = p.Cmdline
 
 			proc.CPUTimes = &CPUTimes{}
 			proc.CPUTimes.IOWait = signalutils.NewTimeseries(ps.timeseriesMaxSpan)
@@ -134,54 +174,75 @@ func (ps *ProcessStats) processStep() error {
 			proc.MemoryPercent = signalutils.NewTimeseries(ps.timeseriesMaxSpan)
 			proc.MemoryTotal = signalutils.NewTimeseries(ps.timeseriesMaxSpan)
 			proc.MemorySwap = signalutils.NewTimeseries(ps.timeseriesMaxSpan)
+			proc.NumThreads = signalutils.NewTimeseries(ps.timeseriesMaxSpan)
+			proc.VoluntaryCtxSwitches = signalutils.NewTimeseriesCounterRate(ps.timeseriesMaxSpan)
+			proc.InvoluntaryCtxSwitches = signalutils.NewTimeseriesCounterRate(ps.timeseriesMaxSpan)
+			proc.UID = -1
 
+			ps.m.Lock()
 			ps.Processes[p.Pid] = proc
+			ps.m.Unlock()
+		}
+		if p.Cgroup != proc.Cgroup {
+			ps.setContainerInfo(proc, p.Cgroup)
 		}
 		addProcessStats(p, proc, ps.timeseriesMaxSpan)
+		setProcessState(proc, p.State, proc.LastSeen, ps.timeseriesMaxSpan)
+		if p.UID != nil && p.NumThreads != nil {
+			userThreads[*p.UID] += int(*p.NumThreads)
+		}
 	}
+	ps.UserThreads = userThreads
+	ps.trackRestarts(processes, time.Now())
 
 	return nil
 }
 
-func addProcessStats(p *process.Process, proc *ProcessMetrics, timeseriesMaxSpan time.Duration) {
-	var err error
+//setContainerInfo attributes a process to the container it runs in
+func (ps *ProcessStats) setContainerInfo(proc *ProcessMetrics, cgroup string) {
+	ci := ParseContainerInfo(cgroup)
+	proc.Cgroup = ci.Cgroup
+	proc.ContainerID = ci.ContainerID
+	proc.PodUID = ci.PodUID
+	proc.ContainerName = ""
+	if ci.ContainerID == "" {
+		return
+	}
+	name, ok := ps.containerNames[ci.ContainerID]
+	if !ok {
+		name = resolveContainerName(ci.ContainerID)
+		ps.containerNames[ci.ContainerID] = name
+	}
+	proc.ContainerName = name
+}
+
+func addProcessStats(p *ProcessSample, proc *ProcessMetrics, timeseriesMaxSpan time.Duration) {
 	proc.LastSeen = time.Now()
 
 	//cpu usage
-	timestats, err := p.Times()
-	if err != nil {
-		logrus.Warnf("Error getting process CPUTimes for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.CPUTimes.IOWait.Add(timestats.Iowait)
-		proc.CPUTimes.Idle.Add(timestats.Idle)
-		proc.CPUTimes.Steal.Add(timestats.Steal)
-		proc.CPUTimes.System.Add(timestats.System)
-		proc.CPUTimes.User.Add(timestats.User)
+	if p.Times != nil {
+		proc.CPUTimes.IOWait.Add(p.Times.Iowait)
+		proc.CPUTimes.Idle.Add(p.Times.Idle)
+		proc.CPUTimes.Steal.Add(p.Times.Steal)
+		proc.CPUTimes.System.Add(p.Times.System)
+		proc.CPUTimes.User.Add(p.Times.User)
 	}
 
 	//network connection count
-	connstats, err := p.Connections()
-	if err != nil {
-		logrus.Warnf("Error getting process Connections for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.Connections.Add(float64(len(connstats)))
+	if p.Connections != nil {
+		proc.Connections.Add(float64(*p.Connections))
+	}
+	if p.Listens != nil {
+		proc.Listens = p.Listens
 	}
 
 	//network io overall
-	netiostats, err := p.NetIOCounters(false)
-	if err != nil {
-		logrus.Warnf("Error getting process overall NETIOCounters for pid=%d; err=%s", p.Pid, err)
-	} else {
-		n := &netiostats[0]
-		addNetIOCounters(n, proc.TotalNetIOCounters)
+	if p.TotalNetIOCounters != nil {
+		addNetIOCounters(p.TotalNetIOCounters, proc.TotalNetIOCounters)
 	}
 
 	//network io per interface
-	netiostats, err = p.NetIOCounters(true)
-	if err != nil {
-		logrus.Warnf("Error getting process NETIOCounters per nic for pid=%d; err=%s", p.Pid, err)
-	}
-	for _, niostat := range netiostats {
+	for _, niostat := range p.NetIOCounters {
 		nc, ok := proc.NetIOCounters[niostat.Name]
 		if !ok {
 			nc = &NetIOCounters{}
@@ -198,48 +259,57 @@ func addProcessStats(p *process.Process, proc *ProcessMetrics, timeseriesMaxSpan
 	}
 
 	//io counters
-	cs, err := p.IOCounters()
-	if err != nil {
-		logrus.Warnf("Error getting process IOCounters for pid=%d; err=%s", p.Pid, err)
-	} else {
+	if p.IOCounters != nil {
 		ioc := proc.IOCounters
-		ioc.ReadBytes.Set(float64(cs.ReadBytes))
-		ioc.ReadCount.Set(float64(cs.ReadCount))
-		ioc.WriteBytes.Set(float64(cs.WriteBytes))
-		ioc.WriteCount.Set(float64(cs.WriteCount))
+		ioc.ReadBytes.Set(float64(p.IOCounters.ReadBytes))
+		ioc.ReadCount.Set(float64(p.IOCounters.ReadCount))
+		ioc.WriteBytes.Set(float64(p.IOCounters.WriteBytes))
+		ioc.WriteCount.Set(float64(p.IOCounters.WriteCount))
 	}
 
 	//ram memory
-	mp, err := p.MemoryPercent()
-	if err != nil {
-		logrus.Warnf("Error getting process MemoryPercent for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.MemoryPercent.Add(float64(mp))
+	if p.MemoryPercent != nil {
+		proc.MemoryPercent.Add(float64(*p.MemoryPercent))
 	}
 
-	mi, err := p.MemoryInfo()
-	if err != nil {
-		logrus.Warnf("Error getting process MemoryInfo for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.MemoryTotal.Add(float64(mi.RSS))
-		proc.MemorySwap.Add(float64(mi.Swap))
+	if p.MemoryInfo != nil {
+		proc.MemoryTotal.Add(float64(p.MemoryInfo.RSS))
+		proc.MemorySwap.Add(float64(p.MemoryInfo.Swap))
 	}
 
 	//file descriptors
-	fd, err := p.NumFDs()
-	if err != nil {
-		logrus.Warnf("Error getting process NumFDs for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.FD.Add(float64(fd))
+	if p.NumFDs != nil {
+		proc.FD.Add(float64(*p.NumFDs))
 	}
 
 	//open files
-	of, err := p.OpenFiles()
-	if err != nil {
-		logrus.Warnf("Error getting process OpenFiles for pid=%d; err=%s", p.Pid, err)
-	} else {
-		proc.OpenFiles.Add(float64(len(of)))
+	if p.OpenFiles != nil {
+		proc.OpenFiles.Add(float64(*p.OpenFiles))
+	}
+
+	//threads and resource limits
+	if p.UID != nil {
+		proc.UID = *p.UID
+	}
+	if p.NumThreads != nil {
+		proc.NumThreads.Add(float64(*p.NumThreads))
+	}
+	if p.Limits != nil {
+		proc.Limits = *p.Limits
+	}
+
+	//context switches
+	if p.CtxSwitches != nil {
+		proc.VoluntaryCtxSwitches.Set(float64(p.CtxSwitches.Voluntary))
+		proc.InvoluntaryCtxSwitches.Set(float64(p.CtxSwitches.Involuntary))
 	}
+
+	//process state
+	if p.PPid != nil {
+		proc.PPid = *p.PPid
+	}
+	proc.Wchan = p.Wchan
+	proc.Cwd = p.Cwd
 }
 
 func addNetIOCounters(n *net.IOCountersStat, nioc *NetIOCounters) {
@@ -443,7 +513,48 @@ func (p *ProcessStats) TopNetConnCount() []*ProcessMetrics {
 	return pa
 }
 
+func (p *ProcessStats) TopThreads() []*ProcessMetrics {
+	pa := p.processesArray()
+	to := time.Now()
+	from := to.Add(-p.cpuLoadTimeSpan)
+	sort.Slice(pa, func(i, j int) bool {
+		pi := pa[i]
+		pj := pa[j]
+
+		si, _ := pi.NumThreads.Avg(from, to)
+		sj, _ := pj.NumThreads.Avg(from, to)
+		return sj < si
+	})
+	return pa
+}
+
+func (p *ProcessStats) TopCtxSwitchRate(voluntary bool) []*ProcessMetrics {
+	pa := p.processesArray()
+	sort.Slice(pa, func(i, j int) bool {
+		pi := pa[i]
+		pj := pa[j]
+
+		if voluntary {
+			vi, _ := pi.VoluntaryCtxSwitches.Rate(p.cpuLoadTimeSpan)
+			vj, _ := pj.VoluntaryCtxSwitches.Rate(p.cpuLoadTimeSpan)
+			return vj < vi
+		}
+
+		ii, _ := pi.InvoluntaryCtxSwitches.Rate(p.cpuLoadTimeSpan)
+		ij, _ := pj.InvoluntaryCtxSwitches.Rate(p.cpuLoadTimeSpan)
+		return ij < ii
+	})
+	return pa
+}
+
+//ProcessesSnapshot returns the processes seen recently. It can be called while stats are being collected
+func (p *ProcessStats) ProcessesSnapshot() []*ProcessMetrics {
+	return p.processesArray()
+}
+
 func (p *ProcessStats) processesArray() []*ProcessMetrics {
+	p.m.RLock()
+	defer p.m.RUnlock()
 	pa := make([]*ProcessMetrics, 0)
 	for _, v := range p.Processes {
 		pa = append(pa, v)
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//readProcessLimits reads open files and processes limits from /proc/<pid>/limits
+//Lines are like "Max open files            1024                 524288               files"
+func readProcessLimits(file string) (ProcessLimits, error) {
+	pl := ProcessLimits{}
+	f, err := os.Open(file)
+	if err != nil {
+		return pl, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var soft, hard *uint64
+		switch {
+		case strings.HasPrefix(line, "Max open files"):
+			soft, hard = &pl.NOFILESoft, &pl.NOFILEHard
+			line = strings.TrimPrefix(line, "Max open files")
+		case strings.HasPrefix(line, "Max processes"):
+			soft, hard = &pl.NPROCSoft, &pl.NPROCHard
+			line = strings.TrimPrefix(line, "Max processes")
+		default:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return pl, fmt.Errorf("Invalid limits line %s in %s", scanner.Text(), file)
+		}
+		*soft, err = parseLimit(fields[0])
+		if err == nil {
+			*hard, err = parseLimit(fields[1])
+		}
+		if err != nil {
+			return pl, fmt.Errorf("Invalid limit value in %s. err=%s", file, err)
+		}
+	}
+	return pl, scanner.Err()
+}
+
+//parseLimit parses a limit value. "unlimited" is returned as 0
+func parseLimit(v string) (uint64, error) {
+	if v == "unlimited" {
+		return 0, nil
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProcessLimits(t *testing.T) {
+	pl, err := readProcessLimits("testdata/hostroot/proc/1/limits")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1024), pl.NOFILESoft)
+	assert.Equal(t, uint64(524288), pl.NOFILEHard)
+	//unlimited
+	assert.Equal(t, uint64(0), pl.NPROCSoft)
+	assert.Equal(t, uint64(0), pl.NPROCHard)
+
+	pl, err = readProcessLimits("testdata/hostroot/proc/2/limits")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(65536), pl.NOFILESoft)
+	assert.Equal(t, uint64(4096), pl.NPROCSoft)
+	assert.Equal(t, uint64(8192), pl.NPROCHard)
+
+	_, err = readProcessLimits("testdata/hostroot/proc/none/limits")
+	assert.NotNil(t, err)
+}
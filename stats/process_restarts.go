@@ -0,0 +1,128 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+//restartMaxSamples max number of sample periods between an exit and the start of a new process
+//with the same command line for it to be a restart, when the processes don't have the same parent
+const restartMaxSamples = 3
+
+//CommandRestarts restarts of processes with the same command line
+type CommandRestarts struct {
+	Cmdline string
+	Name    string
+	//Restarts times when a process of this command was restarted, in the restarts span
+	Restarts []time.Time
+	//LastExit last time an exited process of this command was seen
+	LastExit time.Time
+	//pendingExits exited processes that were not replaced by a new one yet
+	pendingExits []processExit
+}
+
+type processExit struct {
+	lastSeen time.Time
+	ppid     int32
+}
+
+//RestartCount number of restarts since from
+func (c *CommandRestarts) RestartCount(from time.Time) int {
+	count := 0
+	for _, t := range c.Restarts {
+		if !t.Before(from) {
+			count++
+		}
+	}
+	return count
+}
+
+//trackRestarts compares the pids of the current sample with the previous one
+//A process that starts after another process with the same command line exited is a restart if
+//it was started by the same parent (ex.: a supervisor) or right after the exit (within restartMaxSamples)
+//Commands that are run periodically by different parents (ex.: cron jobs) are not restarts
+//Processes without command line (kernel threads, zombies) are ignored
+func (ps *ProcessStats) trackRestarts(processes []ProcessSample, now time.Time) {
+	pids := make(map[int32]bool)
+	for _, p := range processes {
+		pids[p.Pid] = true
+	}
+
+	//first sample. all processes are new
+	if ps.lastPids == nil {
+		ps.lastPids = pids
+		return
+	}
+
+	for pid := range ps.lastPids {
+		if pids[pid] {
+			continue
+		}
+		proc, ok := ps.Processes[pid]
+		if !ok || proc.Cmdline == "" {
+			continue
+		}
+		c, ok := ps.Commands[proc.Cmdline]
+		if !ok {
+			c = &CommandRestarts{Cmdline: proc.Cmdline, Name: proc.Name}
+			ps.Commands[proc.Cmdline] = c
+		}
+		c.LastExit = proc.LastSeen
+		c.pendingExits = append(c.pendingExits, processExit{lastSeen: proc.LastSeen, ppid: proc.PPid})
+	}
+
+	for pid := range pids {
+		if ps.lastPids[pid] {
+			continue
+		}
+		proc, ok := ps.Processes[pid]
+		if !ok {
+			continue
+		}
+		c, ok := ps.Commands[proc.Cmdline]
+		if !ok {
+			continue
+		}
+		for i, e := range c.pendingExits {
+			sameParent := e.ppid != 0 && e.ppid == proc.PPid
+			if sameParent || now.Sub(e.lastSeen) <= restartMaxSamples*ps.samplePeriod {
+				c.pendingExits = append(c.pendingExits[:i], c.pendingExits[i+1:]...)
+				c.Restarts = append(c.Restarts, now)
+				break
+			}
+		}
+	}
+
+	//forget old restarts and exits and commands that didn't restart for a while
+	for cmdline, c := range ps.Commands {
+		i := 0
+		for i < len(c.Restarts) && now.Sub(c.Restarts[i]) > ps.restartsMaxSpan {
+			i++
+		}
+		c.Restarts = c.Restarts[i:]
+		exits := make([]processExit, 0)
+		for _, e := range c.pendingExits {
+			if now.Sub(e.lastSeen) <= ps.restartsMaxSpan {
+				exits = append(exits, e)
+			}
+		}
+		c.pendingExits = exits
+		if len(c.Restarts) == 0 && now.Sub(c.LastExit) > ps.restartsMaxSpan {
+			delete(ps.Commands, cmdline)
+		}
+	}
+
+	ps.lastPids = pids
+}
+
+//TopRestarts commands sorted by number of restarts since from
+func (ps *ProcessStats) TopRestarts(from time.Time) []*CommandRestarts {
+	ca := make([]*CommandRestarts, 0)
+	for _, c := range ps.Commands {
+		ca = append(ca, c)
+	}
+	sort.Slice(ca, func(i, j int) bool {
+		return ca[j].RestartCount(from) < ca[i].RestartCount(from)
+	})
+	return ca
+}
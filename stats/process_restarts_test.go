@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackRestarts(t *testing.T) {
+	//app is restarted with a new pid every 2 samples
+	//job exits and is not started again. kernel threads have no command line
+	tl := Timeline{}
+	for i := 0; i < 10; i++ {
+		ps := []ProcessSample{
+			{Pid: 1, Name: "init", Cmdline: "/sbin/init"},
+			{Pid: 2, Name: "kthreadd"},
+			{Pid: int32(100 + i/2), Name: "app", Cmdline: "/usr/bin/app --port 80"},
+		}
+		if i < 3 {
+			ps = append(ps, ProcessSample{Pid: 50, Name: "job", Cmdline: "/usr/bin/job"})
+		}
+		tl.Processes = append(tl.Processes, ps)
+	}
+
+	ps := &ProcessStats{
+		Processes:         make(map[int32]*ProcessMetrics),
+		Commands:          make(map[string]*CommandRestarts),
+		timeseriesMaxSpan: 1 * time.Minute,
+		restartsMaxSpan:   1 * time.Minute,
+		samplePeriod:      1 * time.Second,
+		source:            NewScriptedSource(tl),
+		containerNames:    make(map[string]string),
+	}
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, ps.processStep())
+	}
+
+	app := ps.Commands["/usr/bin/app --port 80"]
+	if assert.NotNil(t, app) {
+		assert.Equal(t, "app", app.Name)
+		assert.Equal(t, 4, app.RestartCount(start))
+		assert.Equal(t, 0, app.RestartCount(time.Now().Add(time.Second)))
+		assert.False(t, app.LastExit.IsZero())
+	}
+
+	job := ps.Commands["/usr/bin/job"]
+	if assert.NotNil(t, job) {
+		assert.Equal(t, 0, job.RestartCount(start))
+	}
+	assert.Equal(t, 2, len(ps.Commands))
+
+	top := ps.TopRestarts(start)
+	assert.Equal(t, "app", top[0].Name)
+}
+
+//stepRestarts simulates a process sample at now
+func stepRestarts(ps *ProcessStats, now time.Time, samples ...ProcessSample) {
+	for _, p := range samples {
+		proc, ok := ps.Processes[p.Pid]
+		if !ok {
+			proc = &ProcessMetrics{Pid: p.Pid, Name: p.Name, Cmdline: p.Cmdline, PPid: *p.PPid}
+			ps.Processes[p.Pid] = proc
+		}
+		proc.LastSeen = now
+	}
+	ps.trackRestarts(samples, now)
+}
+
+func restartSample(pid int32, ppid int32, cmdline string) ProcessSample {
+	return ProcessSample{Pid: pid, Name: cmdline, Cmdline: cmdline, PPid: &ppid}
+}
+
+func TestTrackRestartsPeriodicCommand(t *testing.T) {
+	ps := &ProcessStats{
+		Processes:         make(map[int32]*ProcessMetrics),
+		Commands:          make(map[string]*CommandRestarts),
+		timeseriesMaxSpan: 10 * time.Minute,
+		restartsMaxSpan:   10 * time.Minute,
+		samplePeriod:      1 * time.Second,
+	}
+	start := time.Now()
+	initProc := restartSample(1, 0, "/sbin/init")
+	supervisor := restartSample(10, 1, "/usr/bin/supervisord")
+	stepRestarts(ps, start, initProc, supervisor)
+
+	//backup is run every minute by a new cron process and lives for 2s
+	//api is restarted by its supervisor after a 30s backoff
+	for m := 0; m < 5; m++ {
+		t0 := start.Add(time.Duration(m) * time.Minute)
+		backup := restartSample(int32(1000+m), int32(500+m), "/usr/bin/backup")
+		api := restartSample(int32(2000+m), 10, "/usr/bin/api")
+		for s := 1; s <= 2; s++ {
+			stepRestarts(ps, t0.Add(time.Duration(s)*time.Second), initProc, supervisor, backup, api)
+		}
+		stepRestarts(ps, t0.Add(3*time.Second), initProc, supervisor)
+		stepRestarts(ps, t0.Add(30*time.Second), initProc, supervisor)
+	}
+
+	from := start.Add(-time.Minute)
+	assert.Equal(t, 0, ps.Commands["/usr/bin/backup"].RestartCount(from))
+	assert.Equal(t, 4, ps.Commands["/usr/bin/api"].RestartCount(from))
+}
+
+func TestTrackRestartsSpanLongerThanTimeseries(t *testing.T) {
+	ps := &ProcessStats{
+		Processes:         make(map[int32]*ProcessMetrics),
+		Commands:          make(map[string]*CommandRestarts),
+		timeseriesMaxSpan: 1 * time.Minute,
+		restartsMaxSpan:   10 * time.Minute,
+		samplePeriod:      1 * time.Second,
+	}
+	start := time.Now()
+	stepRestarts(ps, start, restartSample(100, 1, "/usr/bin/api"))
+	//restarted every 2 minutes
+	for m := 1; m <= 4; m++ {
+		stepRestarts(ps, start.Add(time.Duration(2*m)*time.Minute), restartSample(int32(100+m), 1, "/usr/bin/api"))
+	}
+	now := start.Add(8 * time.Minute)
+	assert.Equal(t, 4, ps.Commands["/usr/bin/api"].RestartCount(now.Add(-10*time.Minute)))
+	assert.Equal(t, 1, ps.Commands["/usr/bin/api"].RestartCount(now.Add(-1*time.Minute)))
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	//ProcessStateRunning process is running or runnable
+	ProcessStateRunning = "R"
+	//ProcessStateSleeping process is in interruptible sleep
+	ProcessStateSleeping = "S"
+	//ProcessStateUninterruptible process is in uninterruptible sleep, usually waiting for io
+	ProcessStateUninterruptible = "D"
+	//ProcessStateZombie process exited but was not reaped by its parent
+	ProcessStateZombie = "Z"
+)
+
+//ProcessStateChange time when a process changed to a state
+type ProcessStateChange struct {
+	Time  time.Time
+	State string
+}
+
+//setProcessState records a state transition if the state changed since the last sample
+//Transitions older than timeseriesMaxSpan are discarded, but the current state is always kept
+func setProcessState(proc *ProcessMetrics, state string, now time.Time, timeseriesMaxSpan time.Duration) {
+	if state == "" {
+		return
+	}
+	if state != proc.State {
+		proc.State = state
+		proc.StateChanges = append(proc.StateChanges, ProcessStateChange{Time: now, State: state})
+	}
+	i := 0
+	for i < len(proc.StateChanges)-1 && now.Sub(proc.StateChanges[i].Time) > timeseriesMaxSpan {
+		i++
+	}
+	proc.StateChanges = proc.StateChanges[i:]
+}
+
+//StateDuration time since the process is in its current state
+//Processes found in a state when first seen are considered in that state since then
+func (proc *ProcessMetrics) StateDuration() time.Duration {
+	if len(proc.StateChanges) == 0 {
+		return 0
+	}
+	return time.Now().Sub(proc.StateChanges[len(proc.StateChanges)-1].Time)
+}
+
+//readWchan reads the kernel function a process is waiting in from /proc/<pid>/wchan
+//Returns "" if the file can't be read or if the kernel hides it ("0")
+func readWchan(file string) string {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	w := strings.TrimSpace(string(b))
+	if w == "0" {
+		return ""
+	}
+	return w
+}
+
+//MountOf returns the mountpoint of the host partition that contains path
+//Returns "" if it can't be determined
+func MountOf(path string) string {
+	if path == "" {
+		return ""
+	}
+	parts, err := hostPartitions()
+	if err != nil {
+		return ""
+	}
+	path = filepath.Clean(path)
+	mount := ""
+	for _, p := range parts {
+		mp := filepath.Clean(p.Mountpoint)
+		if path != mp && !strings.HasPrefix(path, strings.TrimSuffix(mp, "/")+"/") {
+			continue
+		}
+		if len(mp) > len(mount) {
+			mount = mp
+		}
+	}
+	return mount
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProcessState(t *testing.T) {
+	proc := &ProcessMetrics{}
+	now := time.Now()
+	setProcessState(proc, "S", now.Add(-50*time.Second), 30*time.Second)
+	setProcessState(proc, "S", now.Add(-40*time.Second), 30*time.Second)
+	assert.Equal(t, 1, len(proc.StateChanges))
+
+	setProcessState(proc, "D", now.Add(-20*time.Second), 30*time.Second)
+	assert.Equal(t, "D", proc.State)
+	assert.Equal(t, 2, len(proc.StateChanges))
+	assert.InDelta(t, 20, proc.StateDuration().Seconds(), 1)
+
+	//unknown state is ignored
+	setProcessState(proc, "", now, 30*time.Second)
+	assert.Equal(t, "D", proc.State)
+
+	//old transitions are discarded
+	setProcessState(proc, "D", now, 30*time.Second)
+	assert.Equal(t, 1, len(proc.StateChanges))
+	assert.Equal(t, "D", proc.StateChanges[0].State)
+
+	//current state is kept even if older than the span
+	setProcessState(proc, "D", now.Add(time.Minute), 30*time.Second)
+	assert.Equal(t, 1, len(proc.StateChanges))
+}
+
+func TestReadWchan(t *testing.T) {
+	assert.Equal(t, "nfs_wait_bit_killable", readWchan("testdata/hostroot/proc/1/wchan"))
+	assert.Equal(t, "", readWchan("testdata/hostroot/proc/2/wchan"))
+	assert.Equal(t, "", readWchan("testdata/hostroot/proc/none/wchan"))
+}
+
+func TestMountOf(t *testing.T) {
+	SetHostRoot("testdata/hostroot")
+	defer SetHostRoot("")
+	assert.Equal(t, "/data", MountOf("/data/db/files"))
+	assert.Equal(t, "/data", MountOf("/data"))
+	assert.Equal(t, "/", MountOf("/database"))
+	assert.Equal(t, "", MountOf(""))
+}
@@ -15,7 +15,7 @@ func TestProcessStatsBasic(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	ps := NewProcessStats(ctx, 120*time.Second, 1*time.Second, 1*time.Second, 1*time.Second, 1.0)
+	ps := NewProcessStats(ctx, nil, 120*time.Second, 1*time.Second, 1*time.Second, 1*time.Second, 10*time.Minute, 1.0)
 	time.Sleep(7 * time.Second)
 	assert.GreaterOrEqual(t, len(ps.Processes), 1)
 	for _, p := range ps.Processes {
@@ -0,0 +1,532 @@
+package stats
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+	"github.com/sirupsen/logrus"
+)
+
+//recordingVersion version of the recording file format
+const recordingVersion = 1
+
+//RecordingHeader info about a recorded session
+type RecordingHeader struct {
+	Version    int
+	SampleFreq float64
+	Start      time.Time
+}
+
+//Recording a session recorded by RecordingSource
+type Recording struct {
+	Header   RecordingHeader
+	Timeline Timeline
+}
+
+//recordEntry a single sample. Only the field indicated by Kind is set
+type recordEntry struct {
+	Kind       string
+	When       time.Time
+	CPU        *CPUSample
+	Mem        *MemSample
+	FD         *FDSample
+	DiskIO     map[string]disk.IOCountersStat
+	Partitions []disk.UsageStat
+	NICs       []net.IOCountersStat
+	Processes  []ProcessSample
+	Cgroups    []CgroupSample
+	Pressure   *PressureSample
+	NetSNMP    *NetSNMPSample
+	Sockets    *SocketSample
+	Load       *LoadSample
+	Tasks      *TaskSample
+}
+
+//RecordingSource a Source that writes every sample read from another Source
+//to a gzipped gob stream, so it can be replayed later with ReadRecording
+type RecordingSource struct {
+	source Source
+	gz     *gzip.Writer
+	enc    *gob.Encoder
+	err    error
+	m      sync.Mutex
+}
+
+//NewRecordingSource creates a Source that records samples from source to w
+//sampleFreq is the frequency used by the collectors, used to replay samples with the original timing
+func NewRecordingSource(source Source, w io.Writer, sampleFreq float64) (*RecordingSource, error) {
+	gz := gzip.NewWriter(w)
+	r := &RecordingSource{
+		source: sourceOrDefault(source),
+		gz:     gz,
+		enc:    gob.NewEncoder(gz),
+	}
+	err := r.enc.Encode(RecordingHeader{
+		Version:    recordingVersion,
+		SampleFreq: sampleFreq,
+		Start:      time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Close flushes recorded samples. The underlying writer is not closed
+func (r *RecordingSource) Close() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	err := r.gz.Close()
+	if r.err != nil {
+		return r.err
+	}
+	r.err = fmt.Errorf("Recording closed")
+	return err
+}
+
+//record writes an entry. After the first error, samples are not recorded anymore
+//but they are still returned to collectors
+func (r *RecordingSource) record(e recordEntry) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.err != nil {
+		return
+	}
+	e.When = time.Now()
+	r.err = r.enc.Encode(e)
+	if r.err != nil {
+		logrus.Warnf("Error recording sample. Recording stopped. err=%s", r.err)
+	}
+}
+
+func (r *RecordingSource) CPUCount() (int, error) {
+	return r.source.CPUCount()
+}
+
+func (r *RecordingSource) CPUTimes() (CPUSample, error) {
+	s, err := r.source.CPUTimes()
+	if err == nil {
+		r.record(recordEntry{Kind: "cpu", CPU: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Memory() (MemSample, error) {
+	s, err := r.source.Memory()
+	if err == nil {
+		r.record(recordEntry{Kind: "mem", Mem: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) FileDescriptors() (FDSample, error) {
+	s, err := r.source.FileDescriptors()
+	if err == nil {
+		r.record(recordEntry{Kind: "fd", FD: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) DiskIOCounters() (map[string]disk.IOCountersStat, error) {
+	s, err := r.source.DiskIOCounters()
+	if err == nil {
+		r.record(recordEntry{Kind: "diskio", DiskIO: s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Partitions() ([]disk.UsageStat, error) {
+	s, err := r.source.Partitions()
+	if err == nil {
+		r.record(recordEntry{Kind: "partitions", Partitions: s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) NetIOCounters() ([]net.IOCountersStat, error) {
+	s, err := r.source.NetIOCounters()
+	if err == nil {
+		r.record(recordEntry{Kind: "nics", NICs: s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Processes() ([]ProcessSample, error) {
+	s, err := r.source.Processes()
+	if err == nil {
+		r.record(recordEntry{Kind: "processes", Processes: s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Cgroups() ([]CgroupSample, error) {
+	s, err := r.source.Cgroups()
+	if err == nil {
+		r.record(recordEntry{Kind: "cgroups", Cgroups: s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Pressure() (PressureSample, error) {
+	s, err := r.source.Pressure()
+	if err == nil {
+		r.record(recordEntry{Kind: "pressure", Pressure: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) NetSNMP() (NetSNMPSample, error) {
+	s, err := r.source.NetSNMP()
+	if err == nil {
+		r.record(recordEntry{Kind: "netsnmp", NetSNMP: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Sockets() (SocketSample, error) {
+	s, err := r.source.Sockets()
+	if err == nil {
+		r.record(recordEntry{Kind: "sockets", Sockets: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Load() (LoadSample, error) {
+	s, err := r.source.Load()
+	if err == nil {
+		r.record(recordEntry{Kind: "load", Load: &s})
+	}
+	return s, err
+}
+
+func (r *RecordingSource) Tasks() (TaskSample, error) {
+	s, err := r.source.Tasks()
+	if err == nil {
+		r.record(recordEntry{Kind: "tasks", Tasks: &s})
+	}
+	return s, err
+}
+
+//ReadRecording reads a session recorded by RecordingSource
+//A truncated stream (ex.: recorder was killed) returns the samples read until the truncation point
+func ReadRecording(rd io.Reader) (*Recording, error) {
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid recording. err=%s", err)
+	}
+	dec := gob.NewDecoder(gz)
+
+	rec := &Recording{}
+	err = dec.Decode(&rec.Header)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid recording header. err=%s", err)
+	}
+	if rec.Header.Version != recordingVersion {
+		return nil, fmt.Errorf("Unsupported recording version %d", rec.Header.Version)
+	}
+
+	tl := &rec.Timeline
+	for {
+		var e recordEntry
+		err = dec.Decode(&e)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				logrus.Warnf("Recording is truncated. Using samples read so far")
+				break
+			}
+			return nil, fmt.Errorf("Invalid recording entry. err=%s", err)
+		}
+		switch e.Kind {
+		case "cpu":
+			tl.CPU = append(tl.CPU, *e.CPU)
+		case "mem":
+			tl.Mem = append(tl.Mem, *e.Mem)
+		case "fd":
+			tl.FD = append(tl.FD, *e.FD)
+		case "diskio":
+			tl.DiskIO = append(tl.DiskIO, e.DiskIO)
+		case "partitions":
+			tl.Partitions = append(tl.Partitions, e.Partitions)
+		case "nics":
+			tl.NICs = append(tl.NICs, e.NICs)
+		case "processes":
+			tl.Processes = append(tl.Processes, e.Processes)
+		case "cgroups":
+			tl.Cgroups = append(tl.Cgroups, e.Cgroups)
+		case "pressure":
+			tl.Pressure = append(tl.Pressure, *e.Pressure)
+		case "netsnmp":
+			tl.NetSNMP = append(tl.NetSNMP, *e.NetSNMP)
+		case "sockets":
+			tl.Sockets = append(tl.Sockets, *e.Sockets)
+		case "load":
+			tl.Load = append(tl.Load, *e.Load)
+		case "tasks":
+			tl.Tasks = append(tl.Tasks, *e.Tasks)
+		default:
+			logrus.Debugf("Ignoring unknown recording entry kind %s", e.Kind)
+		}
+	}
+	return rec, nil
+}
+
+//Accelerated returns a copy of the timeline for being replayed speed times faster
+//than it was recorded. Cumulative counters (cpu times, io bytes, packets etc) are
+//scaled down relative to their first sample so that rates calculated over wall clock
+//time during replay match the recorded ones. Gauges (memory, disk space etc) are kept
+func (t Timeline) Accelerated(speed float64) Timeline {
+	if speed == 1 || speed <= 0 {
+		return t
+	}
+	a := Timeline{
+		Mem:        make([]MemSample, len(t.Mem)),
+		FD:         t.FD,
+		Partitions: t.Partitions,
+		Sockets:    t.Sockets,
+		Tasks:      t.Tasks,
+	}
+
+	var firstCPU *CPUSample
+	for i, s := range t.CPU {
+		if firstCPU == nil {
+			firstCPU = &t.CPU[i]
+		}
+		c := CPUSample{
+			Total: scaleTimes(firstCPU.Total, s.Total, speed),
+			CPUs:  make([]cpu.TimesStat, len(s.CPUs)),
+		}
+		for i, ct := range s.CPUs {
+			f := ct
+			if i < len(firstCPU.CPUs) {
+				f = firstCPU.CPUs[i]
+			}
+			c.CPUs[i] = scaleTimes(f, ct, speed)
+		}
+		a.CPU = append(a.CPU, c)
+	}
+
+	for i, s := range t.Mem {
+		f := t.Mem[0].Swap
+		s.Swap.Sin = scaleUint(f.Sin, s.Swap.Sin, speed)
+		s.Swap.Sout = scaleUint(f.Sout, s.Swap.Sout, speed)
+		s.Swap.PgIn = scaleUint(f.PgIn, s.Swap.PgIn, speed)
+		s.Swap.PgOut = scaleUint(f.PgOut, s.Swap.PgOut, speed)
+		s.Swap.PgFault = scaleUint(f.PgFault, s.Swap.PgFault, speed)
+		a.Mem[i] = s
+	}
+
+	firstDisk := make(map[string]disk.IOCountersStat)
+	for _, s := range t.DiskIO {
+		d := make(map[string]disk.IOCountersStat)
+		for name, c := range s {
+			f, ok := firstDisk[name]
+			if !ok {
+				f = c
+				firstDisk[name] = c
+			}
+			c.ReadCount = scaleUint(f.ReadCount, c.ReadCount, speed)
+			c.MergedReadCount = scaleUint(f.MergedReadCount, c.MergedReadCount, speed)
+			c.WriteCount = scaleUint(f.WriteCount, c.WriteCount, speed)
+			c.MergedWriteCount = scaleUint(f.MergedWriteCount, c.MergedWriteCount, speed)
+			c.ReadBytes = scaleUint(f.ReadBytes, c.ReadBytes, speed)
+			c.WriteBytes = scaleUint(f.WriteBytes, c.WriteBytes, speed)
+			c.ReadTime = scaleUint(f.ReadTime, c.ReadTime, speed)
+			c.WriteTime = scaleUint(f.WriteTime, c.WriteTime, speed)
+			c.IoTime = scaleUint(f.IoTime, c.IoTime, speed)
+			c.WeightedIO = scaleUint(f.WeightedIO, c.WeightedIO, speed)
+			d[name] = c
+		}
+		a.DiskIO = append(a.DiskIO, d)
+	}
+
+	firstNIC := make(map[string]net.IOCountersStat)
+	for _, s := range t.NICs {
+		a.NICs = append(a.NICs, scaleNICs(firstNIC, "", s, speed))
+	}
+
+	firstTimes := make(map[int32]cpu.TimesStat)
+	firstIO := make(map[int32]process.IOCountersStat)
+	firstCtxSwitches := make(map[int32]process.NumCtxSwitchesStat)
+	for _, s := range t.Processes {
+		ps := make([]ProcessSample, len(s))
+		for i, p := range s {
+			if p.Times != nil {
+				f, ok := firstTimes[p.Pid]
+				if !ok {
+					f = *p.Times
+					firstTimes[p.Pid] = f
+				}
+				ts := scaleTimes(f, *p.Times, speed)
+				p.Times = &ts
+			}
+			if p.IOCounters != nil {
+				f, ok := firstIO[p.Pid]
+				if !ok {
+					f = *p.IOCounters
+					firstIO[p.Pid] = f
+				}
+				p.IOCounters = &process.IOCountersStat{
+					ReadCount:  scaleUint(f.ReadCount, p.IOCounters.ReadCount, speed),
+					WriteCount: scaleUint(f.WriteCount, p.IOCounters.WriteCount, speed),
+					ReadBytes:  scaleUint(f.ReadBytes, p.IOCounters.ReadBytes, speed),
+					WriteBytes: scaleUint(f.WriteBytes, p.IOCounters.WriteBytes, speed),
+				}
+			}
+			if p.CtxSwitches != nil {
+				f, ok := firstCtxSwitches[p.Pid]
+				if !ok {
+					f = *p.CtxSwitches
+					firstCtxSwitches[p.Pid] = f
+				}
+				p.CtxSwitches = &process.NumCtxSwitchesStat{
+					Voluntary:   int64(scaleUint(uint64(f.Voluntary), uint64(p.CtxSwitches.Voluntary), speed)),
+					Involuntary: int64(scaleUint(uint64(f.Involuntary), uint64(p.CtxSwitches.Involuntary), speed)),
+				}
+			}
+			prefix := fmt.Sprintf("%d/", p.Pid)
+			if p.TotalNetIOCounters != nil {
+				n := scaleNICs(firstNIC, prefix+"total/", []net.IOCountersStat{*p.TotalNetIOCounters}, speed)
+				p.TotalNetIOCounters = &n[0]
+			}
+			p.NetIOCounters = scaleNICs(firstNIC, prefix, p.NetIOCounters, speed)
+			ps[i] = p
+		}
+		a.Processes = append(a.Processes, ps)
+	}
+
+	//stall averages are kept as they were calculated by the kernel over the recorded time
+	for _, p := range t.Pressure {
+		f := t.Pressure[0]
+		p.CPU = scalePressure(f.CPU, p.CPU, speed)
+		p.Memory = scalePressure(f.Memory, p.Memory, speed)
+		p.IO = scalePressure(f.IO, p.IO, speed)
+		a.Pressure = append(a.Pressure, p)
+	}
+
+	for _, n := range t.NetSNMP {
+		a.NetSNMP = append(a.NetSNMP, scaleNetSNMP(t.NetSNMP[0], n, speed))
+	}
+
+	//load averages are kept as they were calculated by the kernel over the recorded time
+	for _, l := range t.Load {
+		f := t.Load[0]
+		l.CtxSwitches = scaleUint(f.CtxSwitches, l.CtxSwitches, speed)
+		l.Interrupts = scaleUint(f.Interrupts, l.Interrupts, speed)
+		a.Load = append(a.Load, l)
+	}
+
+	firstCgroup := make(map[string]CgroupSample)
+	for _, s := range t.Cgroups {
+		cs := make([]CgroupSample, len(s))
+		for i, c := range s {
+			f, ok := firstCgroup[c.Path]
+			if !ok {
+				f = c
+				firstCgroup[c.Path] = c
+			}
+			c.CPUUsageUsec = scaleUint(f.CPUUsageUsec, c.CPUUsageUsec, speed)
+			c.NrPeriods = scaleUint(f.NrPeriods, c.NrPeriods, speed)
+			c.NrThrottled = scaleUint(f.NrThrottled, c.NrThrottled, speed)
+			c.ThrottledUsec = scaleUint(f.ThrottledUsec, c.ThrottledUsec, speed)
+			c.IOReadBytes = scaleUint(f.IOReadBytes, c.IOReadBytes, speed)
+			c.IOWriteBytes = scaleUint(f.IOWriteBytes, c.IOWriteBytes, speed)
+			c.IOReadOps = scaleUint(f.IOReadOps, c.IOReadOps, speed)
+			c.IOWriteOps = scaleUint(f.IOWriteOps, c.IOWriteOps, speed)
+			cs[i] = c
+		}
+		a.Cgroups = append(a.Cgroups, cs)
+	}
+
+	return a
+}
+
+func scalePressure(first PressureStat, p PressureStat, speed float64) PressureStat {
+	p.Some.Total = scaleUint(first.Some.Total, p.Some.Total, speed)
+	p.Full.Total = scaleUint(first.Full.Total, p.Full.Total, speed)
+	return p
+}
+
+func scaleNetSNMP(f NetSNMPSample, n NetSNMPSample, speed float64) NetSNMPSample {
+	return NetSNMPSample{
+		TCPInSegs:          scaleUint(f.TCPInSegs, n.TCPInSegs, speed),
+		TCPOutSegs:         scaleUint(f.TCPOutSegs, n.TCPOutSegs, speed),
+		TCPRetransSegs:     scaleUint(f.TCPRetransSegs, n.TCPRetransSegs, speed),
+		TCPActiveOpens:     scaleUint(f.TCPActiveOpens, n.TCPActiveOpens, speed),
+		TCPPassiveOpens:    scaleUint(f.TCPPassiveOpens, n.TCPPassiveOpens, speed),
+		TCPAttemptFails:    scaleUint(f.TCPAttemptFails, n.TCPAttemptFails, speed),
+		TCPEstabResets:     scaleUint(f.TCPEstabResets, n.TCPEstabResets, speed),
+		TCPInErrs:          scaleUint(f.TCPInErrs, n.TCPInErrs, speed),
+		TCPOutRsts:         scaleUint(f.TCPOutRsts, n.TCPOutRsts, speed),
+		TCPListenOverflows: scaleUint(f.TCPListenOverflows, n.TCPListenOverflows, speed),
+		TCPListenDrops:     scaleUint(f.TCPListenDrops, n.TCPListenDrops, speed),
+		TCPTimeouts:        scaleUint(f.TCPTimeouts, n.TCPTimeouts, speed),
+		UDPInDatagrams:     scaleUint(f.UDPInDatagrams, n.UDPInDatagrams, speed),
+		UDPOutDatagrams:    scaleUint(f.UDPOutDatagrams, n.UDPOutDatagrams, speed),
+		UDPInErrors:        scaleUint(f.UDPInErrors, n.UDPInErrors, speed),
+		UDPRcvbufErrors:    scaleUint(f.UDPRcvbufErrors, n.UDPRcvbufErrors, speed),
+		UDPSndbufErrors:    scaleUint(f.UDPSndbufErrors, n.UDPSndbufErrors, speed),
+	}
+}
+
+func scaleNICs(first map[string]net.IOCountersStat, prefix string, nics []net.IOCountersStat, speed float64) []net.IOCountersStat {
+	if nics == nil {
+		return nil
+	}
+	sn := make([]net.IOCountersStat, len(nics))
+	for i, c := range nics {
+		f, ok := first[prefix+c.Name]
+		if !ok {
+			f = c
+			first[prefix+c.Name] = c
+		}
+		c.BytesSent = scaleUint(f.BytesSent, c.BytesSent, speed)
+		c.BytesRecv = scaleUint(f.BytesRecv, c.BytesRecv, speed)
+		c.PacketsSent = scaleUint(f.PacketsSent, c.PacketsSent, speed)
+		c.PacketsRecv = scaleUint(f.PacketsRecv, c.PacketsRecv, speed)
+		c.Errin = scaleUint(f.Errin, c.Errin, speed)
+		c.Errout = scaleUint(f.Errout, c.Errout, speed)
+		c.Dropin = scaleUint(f.Dropin, c.Dropin, speed)
+		c.Dropout = scaleUint(f.Dropout, c.Dropout, speed)
+		c.Fifoin = scaleUint(f.Fifoin, c.Fifoin, speed)
+		c.Fifoout = scaleUint(f.Fifoout, c.Fifoout, speed)
+		sn[i] = c
+	}
+	return sn
+}
+
+func scaleTimes(first cpu.TimesStat, t cpu.TimesStat, speed float64) cpu.TimesStat {
+	t.User = scaleFloat(first.User, t.User, speed)
+	t.System = scaleFloat(first.System, t.System, speed)
+	t.Idle = scaleFloat(first.Idle, t.Idle, speed)
+	t.Nice = scaleFloat(first.Nice, t.Nice, speed)
+	t.Iowait = scaleFloat(first.Iowait, t.Iowait, speed)
+	t.Irq = scaleFloat(first.Irq, t.Irq, speed)
+	t.Softirq = scaleFloat(first.Softirq, t.Softirq, speed)
+	t.Steal = scaleFloat(first.Steal, t.Steal, speed)
+	t.Guest = scaleFloat(first.Guest, t.Guest, speed)
+	t.GuestNice = scaleFloat(first.GuestNice, t.GuestNice, speed)
+	return t
+}
+
+func scaleFloat(first float64, v float64, speed float64) float64 {
+	return first + (v-first)/speed
+}
+
+func scaleUint(first uint64, v uint64, speed float64) uint64 {
+	if v < first {
+		//counter was reset
+		return v
+	}
+	return first + uint64(float64(v-first)/speed)
+}
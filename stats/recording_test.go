@@ -0,0 +1,70 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	tl := Timeline{
+		CPU: []CPUSample{
+			{Total: cpu.TimesStat{User: 1}, CPUs: []cpu.TimesStat{{CPU: "cpu0", User: 1}}},
+			{Total: cpu.TimesStat{User: 2}, CPUs: []cpu.TimesStat{{CPU: "cpu0", User: 2}}},
+		},
+		FD:     []FDSample{{Used: 10, Max: 100}},
+		DiskIO: []map[string]disk.IOCountersStat{{"sda": {Name: "sda", WriteBytes: 1000}}},
+		NICs:   [][]net.IOCountersStat{{{Name: "eth0", BytesSent: 10}}},
+	}
+
+	buf := &bytes.Buffer{}
+	rs, err := NewRecordingSource(NewScriptedSource(tl), buf, 2)
+	assert.Nil(t, err)
+	rs.CPUTimes()
+	rs.CPUTimes()
+	rs.FileDescriptors()
+	rs.DiskIOCounters()
+	rs.NetIOCounters()
+	//sequence ended. nothing is recorded
+	_, err = rs.FileDescriptors()
+	assert.Equal(t, ErrTimelineEnd, err)
+	assert.Nil(t, rs.Close())
+
+	rec, err := ReadRecording(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, rec.Header.SampleFreq)
+	assert.Equal(t, tl.CPU, rec.Timeline.CPU)
+	assert.Equal(t, tl.FD, rec.Timeline.FD)
+	assert.Equal(t, tl.DiskIO, rec.Timeline.DiskIO)
+	assert.Equal(t, tl.NICs, rec.Timeline.NICs)
+	assert.Empty(t, rec.Timeline.Processes)
+}
+
+func TestTimelineAccelerated(t *testing.T) {
+	tl := Timeline{
+		CPU: []CPUSample{
+			{Total: cpu.TimesStat{User: 100}},
+			{Total: cpu.TimesStat{User: 110}},
+		},
+		DiskIO: []map[string]disk.IOCountersStat{
+			{"sda": {WriteBytes: 5000, IopsInProgress: 3}},
+			{"sda": {WriteBytes: 7000, IopsInProgress: 3}},
+		},
+		FD: []FDSample{{Used: 10}, {Used: 20}},
+	}
+
+	a := tl.Accelerated(10)
+	assert.Equal(t, 100.0, a.CPU[0].Total.User)
+	assert.InDelta(t, 101.0, a.CPU[1].Total.User, 0.001)
+	assert.Equal(t, uint64(5000), a.DiskIO[0]["sda"].WriteBytes)
+	assert.Equal(t, uint64(5200), a.DiskIO[1]["sda"].WriteBytes)
+	assert.Equal(t, uint64(3), a.DiskIO[1]["sda"].IopsInProgress)
+	assert.Equal(t, tl.FD, a.FD)
+
+	//original timeline is untouched
+	assert.Equal(t, 110.0, tl.CPU[1].Total.User)
+}
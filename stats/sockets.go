@@ -0,0 +1,191 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+//tcpStates names of the states in /proc/net/tcp by their hex code
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+//SocketStats tcp socket states and ephemeral ports usage
+type SocketStats struct {
+	//States number of tcp sockets by state. ex.: ESTABLISHED, TIME_WAIT
+	States map[string]*signalutils.Timeseries
+	//EphemeralPortsUsed ephemeral ports used by connections to EphemeralTopRemote
+	EphemeralPortsUsed signalutils.Timeseries
+	//EphemeralPortsTotal number of ports in ip_local_port_range
+	EphemeralPortsTotal uint64
+	//EphemeralTopRemote remote address (ip:port) with most connections from ephemeral ports
+	EphemeralTopRemote string
+	timeseriesMaxSpan  time.Duration
+	source             Source
+}
+
+func NewSocketStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *SocketStats {
+	logrus.Tracef("Socket Stats: initializing...")
+	s := &SocketStats{
+		States:             make(map[string]*signalutils.Timeseries),
+		EphemeralPortsUsed: signalutils.NewTimeseries(timeseriesMaxSpan),
+		timeseriesMaxSpan:  timeseriesMaxSpan,
+		source:             sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "sockets", s.socketsStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Socket Stats: running")
+	return s
+}
+
+func (s *SocketStats) socketsStep() error {
+	sample, err := s.source.Sockets()
+	if err != nil {
+		return err
+	}
+	for state, count := range sample.States {
+		ts, ok := s.States[state]
+		if !ok {
+			nts := signalutils.NewTimeseries(s.timeseriesMaxSpan)
+			ts = &nts
+			s.States[state] = ts
+		}
+		ts.Add(float64(count))
+	}
+	//states without sockets are not in the sample
+	for state, ts := range s.States {
+		_, ok := sample.States[state]
+		if !ok {
+			ts.Add(0)
+		}
+	}
+	s.EphemeralPortsUsed.Add(float64(sample.EphemeralPortsUsed))
+	s.EphemeralTopRemote = sample.EphemeralTopRemote
+	if sample.EphemeralPortsMax >= sample.EphemeralPortsMin && sample.EphemeralPortsMax > 0 {
+		s.EphemeralPortsTotal = sample.EphemeralPortsMax - sample.EphemeralPortsMin + 1
+	}
+	return nil
+}
+
+//StateCount last number of sockets in a tcp state
+func (s *SocketStats) StateCount(state string) float64 {
+	ts, ok := s.States[state]
+	if !ok {
+		return 0
+	}
+	v, ok := ts.Last()
+	if !ok {
+		return 0
+	}
+	return v.Value
+}
+
+//readSockets counts tcp sockets by state in tcpFiles (/proc/net/tcp and /proc/net/tcp6) and checks
+//the usage of ephemeral ports (portRangeFile is /proc/sys/net/ipv4/ip_local_port_range)
+//Files that don't exist are ignored (ex.: ipv6 disabled)
+func readSockets(portRangeFile string, tcpFiles ...string) (SocketSample, error) {
+	ss := SocketSample{States: make(map[string]uint64)}
+
+	b, err := ioutil.ReadFile(portRangeFile)
+	if err != nil {
+		return ss, fmt.Errorf("Cannot read ephemeral port range. err=%s", err)
+	}
+	pr := strings.Fields(string(b))
+	if len(pr) != 2 {
+		return ss, fmt.Errorf("Invalid ephemeral port range %s", string(b))
+	}
+	ss.EphemeralPortsMin, _ = strconv.ParseUint(pr[0], 10, 64)
+	ss.EphemeralPortsMax, _ = strconv.ParseUint(pr[1], 10, 64)
+
+	//a local port can be reused for connections to different remote addresses,
+	//so ports get exhausted for the remote address with most connections
+	remotes := make(map[string]uint64)
+	for _, file := range tcpFiles {
+		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return ss, err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 4 || fields[0] == "sl" {
+				continue
+			}
+			state, ok := tcpStates[fields[3]]
+			if !ok {
+				continue
+			}
+			ss.States[state]++
+			if state == "LISTEN" {
+				continue
+			}
+			_, lport, err := parseHexAddr(fields[1])
+			if err != nil || lport < ss.EphemeralPortsMin || lport > ss.EphemeralPortsMax {
+				continue
+			}
+			rip, rport, err := parseHexAddr(fields[2])
+			if err != nil {
+				continue
+			}
+			remotes[net.JoinHostPort(rip.String(), strconv.FormatUint(rport, 10))]++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return ss, err
+		}
+	}
+
+	for remote, count := range remotes {
+		if count > ss.EphemeralPortsUsed {
+			ss.EphemeralPortsUsed = count
+			ss.EphemeralTopRemote = remote
+		}
+	}
+	return ss, nil
+}
+
+//parseHexAddr parses addresses like "0100007F:1F90" from /proc/net/tcp. IPs are in host byte order (little endian)
+func parseHexAddr(addr string) (net.IP, uint64, error) {
+	p := strings.Split(addr, ":")
+	if len(p) != 2 {
+		return nil, 0, fmt.Errorf("Invalid address %s", addr)
+	}
+	port, err := strconv.ParseUint(p[1], 16, 16)
+	if err != nil {
+		return nil, 0, err
+	}
+	b, err := hex.DecodeString(p[0])
+	if err != nil || (len(b) != 4 && len(b) != 16) {
+		return nil, 0, fmt.Errorf("Invalid address %s", addr)
+	}
+	//reverse the bytes of each 32 bits word
+	ip := make(net.IP, len(b))
+	for i := 0; i < len(b); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+	return ip, port, nil
+}
@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSockets(t *testing.T) {
+	s, err := readSockets("testdata/hostroot/proc/sys/net/ipv4/ip_local_port_range", "testdata/hostroot/proc/net/tcp", "testdata/hostroot/proc/net/tcp6")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(32768), s.EphemeralPortsMin)
+	assert.Equal(t, uint64(60999), s.EphemeralPortsMax)
+	assert.Equal(t, uint64(3), s.States["LISTEN"])
+	assert.Equal(t, uint64(5), s.States["ESTABLISHED"])
+	assert.Equal(t, uint64(2), s.States["TIME_WAIT"])
+	assert.Equal(t, uint64(1), s.States["CLOSE_WAIT"])
+	//ipv4 mapped ipv6 connections use the same ports
+	assert.Equal(t, uint64(5), s.EphemeralPortsUsed)
+	assert.Equal(t, "10.0.0.2:3306", s.EphemeralTopRemote)
+
+	//tcp6 is optional
+	s, err = readSockets("testdata/hostroot/proc/sys/net/ipv4/ip_local_port_range", "testdata/hostroot/proc/net/tcp", "testdata/hostroot/proc/net/none")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), s.EphemeralPortsUsed)
+
+	_, err = readSockets("testdata/hostroot/proc/sys/net/ipv4/none", "testdata/hostroot/proc/net/tcp")
+	assert.NotNil(t, err)
+}
+
+func TestParseHexAddr(t *testing.T) {
+	ip, port, err := parseHexAddr("0100007F:1F90")
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, uint64(8080), port)
+
+	ip, port, err = parseHexAddr("B80D01200000000000000000010000FF:01BB")
+	assert.Nil(t, err)
+	assert.Equal(t, "2001:db8::ff00:1", ip.String())
+	assert.Equal(t, uint64(443), port)
+
+	_, _, err = parseHexAddr("0100007F")
+	assert.NotNil(t, err)
+}
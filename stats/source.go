@@ -0,0 +1,232 @@
+package stats
+
+import (
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+//Source provides the raw system samples used by stats collectors
+//Each collector step calls its Source method once, so implementations
+//may return a new sample for each call
+type Source interface {
+	//CPUCount number of logical cpus
+	CPUCount() (int, error)
+	//CPUTimes cumulative cpu times for all cpus together and for each cpu
+	CPUTimes() (CPUSample, error)
+	//Memory ram and swap usage
+	Memory() (MemSample, error)
+	//FileDescriptors system wide file descriptors usage
+	FileDescriptors() (FDSample, error)
+	//DiskIOCounters cumulative io counters by disk name
+	DiskIOCounters() (map[string]disk.IOCountersStat, error)
+	//Partitions space and inodes usage for each mounted partition
+	Partitions() ([]disk.UsageStat, error)
+	//NetIOCounters cumulative io counters by NIC
+	NetIOCounters() ([]net.IOCountersStat, error)
+	//Processes info about running processes
+	Processes() ([]ProcessSample, error)
+	//Cgroups cgroup v2 stats for each container cgroup
+	Cgroups() ([]CgroupSample, error)
+	//Pressure pressure stall information for cpu, memory and io
+	Pressure() (PressureSample, error)
+	//NetSNMP tcp and udp stack counters
+	NetSNMP() (NetSNMPSample, error)
+	//Sockets tcp socket states and ephemeral ports usage
+	Sockets() (SocketSample, error)
+	//Load load average, runnable tasks and scheduler counters
+	Load() (LoadSample, error)
+	//Tasks number of tasks (processes and threads) and their kernel limits
+	Tasks() (TaskSample, error)
+}
+
+//CPUSample cumulative cpu times
+type CPUSample struct {
+	Total cpu.TimesStat
+	CPUs  []cpu.TimesStat
+}
+
+//MemSample ram and swap usage
+type MemSample struct {
+	Memory mem.VirtualMemoryStat
+	Swap   mem.SwapMemoryStat
+	//OOMKill cumulative number of processes killed by the OOM killer (oom_kill from /proc/vmstat). 0 if not available
+	OOMKill uint64
+}
+
+//FDSample system wide file descriptors usage
+type FDSample struct {
+	Used int64
+	Max  int64
+}
+
+//ProcessSample info about a running process
+//Pointer and slice fields are nil when the info couldn't be read
+type ProcessSample struct {
+	Pid                int32
+	Name               string
+	Cmdline            string
+	Cgroup             string
+	Times              *cpu.TimesStat
+	Connections        *int
+	TotalNetIOCounters *net.IOCountersStat
+	NetIOCounters      []net.IOCountersStat
+	IOCounters         *process.IOCountersStat
+	MemoryPercent      *float32
+	MemoryInfo         *process.MemoryInfoStat
+	NumFDs             *int32
+	OpenFiles          *int
+	//Listens sockets the process is listening on. ex.: tcp:8080, udp:53
+	Listens []string
+	//UID real user id
+	UID         *int32
+	NumThreads  *int32
+	Limits      *ProcessLimits
+	CtxSwitches *process.NumCtxSwitchesStat
+	PPid        *int32
+	//State R (running), S (sleeping), D (uninterruptible), Z (zombie), T (stopped) etc. Empty if unknown
+	State string
+	//Wchan kernel function the process is waiting in. Only read for processes in D state
+	Wchan string
+	//Cwd current dir. Only read for processes in D state
+	Cwd string
+}
+
+//ProcessLimits resource limits of a process from /proc/<pid>/limits. 0 when unlimited
+type ProcessLimits struct {
+	//NOFILESoft and NOFILEHard max number of open files
+	NOFILESoft uint64
+	NOFILEHard uint64
+	//NPROCSoft and NPROCHard max number of processes and threads of the process real user
+	NPROCSoft uint64
+	NPROCHard uint64
+}
+
+//CgroupSample cgroup v2 stats of a container. Counters are cumulative since the cgroup was created
+//Limits are 0 when not set (max)
+type CgroupSample struct {
+	//Path cgroup path relative to the cgroup root. ex.: /system.slice/docker-<id>.scope
+	Path string
+	//cpu.stat
+	CPUUsageUsec  uint64
+	NrPeriods     uint64
+	NrThrottled   uint64
+	ThrottledUsec uint64
+	//memory.current, memory.max and inactive_file from memory.stat
+	MemoryCurrent      uint64
+	MemoryMax          uint64
+	MemoryInactiveFile uint64
+	//memory.events
+	MemoryEvents CgroupMemoryEvents
+	//io.stat summed for all devices
+	IOReadBytes  uint64
+	IOWriteBytes uint64
+	IOReadOps    uint64
+	IOWriteOps   uint64
+	//pids.current and pids.max
+	PidsCurrent uint64
+	PidsMax     uint64
+}
+
+//CgroupMemoryEvents cumulative counters from memory.events
+type CgroupMemoryEvents struct {
+	Low     uint64
+	High    uint64
+	Max     uint64
+	OOM     uint64
+	OOMKill uint64
+}
+
+//PressureSample pressure stall information (PSI) from /proc/pressure
+//Supported is false when the kernel doesn't provide it (Linux < 4.20 or PSI disabled)
+type PressureSample struct {
+	Supported bool
+	CPU       PressureStat
+	Memory    PressureStat
+	IO        PressureStat
+}
+
+//PressureStat stall time of tasks waiting for a resource
+//Some: at least one task was stalled; Full: all non idle tasks were stalled at the same time
+type PressureStat struct {
+	Some PressureLine
+	Full PressureLine
+}
+
+//PressureLine share of time stalled (0-100) in the last 10s, 60s and 300s and total stall time in microseconds
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+//NetSNMPSample cumulative counters of the kernel tcp and udp stacks from /proc/net/snmp and /proc/net/netstat
+type NetSNMPSample struct {
+	//Tcp
+	TCPInSegs       uint64
+	TCPOutSegs      uint64
+	TCPRetransSegs  uint64
+	TCPActiveOpens  uint64
+	TCPPassiveOpens uint64
+	TCPAttemptFails uint64
+	TCPEstabResets  uint64
+	TCPInErrs       uint64
+	TCPOutRsts      uint64
+	//TcpExt
+	TCPListenOverflows uint64
+	TCPListenDrops     uint64
+	TCPTimeouts        uint64
+	//Udp
+	UDPInDatagrams  uint64
+	UDPOutDatagrams uint64
+	UDPInErrors     uint64
+	UDPRcvbufErrors uint64
+	UDPSndbufErrors uint64
+}
+
+//SocketSample tcp sockets of the host
+type SocketSample struct {
+	//States number of tcp sockets by state. ex.: ESTABLISHED, TIME_WAIT
+	States map[string]uint64
+	//EphemeralPortsMin and EphemeralPortsMax from ip_local_port_range
+	EphemeralPortsMin uint64
+	EphemeralPortsMax uint64
+	//EphemeralPortsUsed local ports in the ephemeral range used by connections to EphemeralTopRemote
+	EphemeralPortsUsed uint64
+	//EphemeralTopRemote remote address (ip:port) with most connections from ephemeral ports
+	EphemeralTopRemote string
+}
+
+//LoadSample load average from /proc/loadavg and scheduler stats from /proc/stat
+type LoadSample struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+	//ProcsRunning tasks running or waiting for a cpu
+	ProcsRunning uint64
+	//ProcsBlocked tasks blocked waiting for io
+	ProcsBlocked uint64
+	//CtxSwitches and Interrupts cumulative counters since boot
+	CtxSwitches uint64
+	Interrupts  uint64
+}
+
+//TaskSample tasks from /proc/loadavg and kernel limits from /proc/sys/kernel
+type TaskSample struct {
+	//Tasks processes and threads currently existing. Each one uses a pid
+	Tasks uint64
+	//PidMax kernel.pid_max. 0 if not available
+	PidMax uint64
+	//ThreadsMax kernel.threads-max. 0 if not available
+	ThreadsMax uint64
+}
+
+func sourceOrDefault(source Source) Source {
+	if source == nil {
+		return NewGopsutilSource()
+	}
+	return source
+}
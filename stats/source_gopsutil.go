@@ -0,0 +1,290 @@
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+	"github.com/sirupsen/logrus"
+)
+
+//GopsutilSource reads samples from the running system using gopsutil
+type GopsutilSource struct {
+	//process name and cmdline don't change, so they are read only once for each pid
+	procInfo map[int32]procInfo
+	m        sync.Mutex
+}
+
+type procInfo struct {
+	name    string
+	cmdline string
+	cgroup  string
+}
+
+//NewGopsutilSource creates a Source that reads from the running system
+func NewGopsutilSource() *GopsutilSource {
+	return &GopsutilSource{
+		procInfo: make(map[int32]procInfo),
+	}
+}
+
+func (s *GopsutilSource) CPUCount() (int, error) {
+	return cpu.Counts(true)
+}
+
+func (s *GopsutilSource) CPUTimes() (CPUSample, error) {
+	total, err := cpu.Times(false)
+	if err != nil {
+		return CPUSample{}, err
+	}
+	if len(total) == 0 {
+		return CPUSample{}, fmt.Errorf("No cpu times found")
+	}
+	cpus, err := cpu.Times(true)
+	if err != nil {
+		return CPUSample{}, err
+	}
+	return CPUSample{Total: total[0], CPUs: cpus}, nil
+}
+
+func (s *GopsutilSource) Memory() (MemSample, error) {
+	ms, err := mem.VirtualMemory()
+	if err != nil {
+		return MemSample{}, fmt.Errorf("Cannot get mem stats. err=%s", err)
+	}
+	ss, err := mem.SwapMemory()
+	if err != nil {
+		return MemSample{}, fmt.Errorf("Cannot get swap stats. err=%s", err)
+	}
+	//oom_kill is available since Linux 4.13
+	oomKill := readKeyValues(HostProc("vmstat"))["oom_kill"]
+	return MemSample{Memory: *ms, Swap: *ss, OOMKill: oomKill}, nil
+}
+
+func (s *GopsutilSource) FileDescriptors() (FDSample, error) {
+	used, max, err := FDStats()
+	if err != nil {
+		return FDSample{}, err
+	}
+	return FDSample{Used: used, Max: max}, nil
+}
+
+func (s *GopsutilSource) DiskIOCounters() (map[string]disk.IOCountersStat, error) {
+	return disk.IOCounters()
+}
+
+func (s *GopsutilSource) Partitions() ([]disk.UsageStat, error) {
+	partitions, err := hostPartitions()
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]disk.UsageStat, 0)
+	for _, p := range partitions {
+		pu, err := hostPartitionUsage(p.Mountpoint)
+		if err != nil {
+			return nil, err
+		}
+		pu.Fstype = p.Fstype
+		usages = append(usages, *pu)
+	}
+	return usages, nil
+}
+
+func (s *GopsutilSource) NetIOCounters() ([]net.IOCountersStat, error) {
+	return hostNetIOCounters()
+}
+
+func (s *GopsutilSource) Processes() ([]ProcessSample, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int32]procInfo)
+	samples := make([]ProcessSample, 0)
+	for _, p := range processes {
+		pi, ok := s.procInfo[p.Pid]
+		if !ok {
+			pi.name, err = p.Name()
+			if err != nil {
+				logrus.Tracef("Couldn't get process name for pid=%d (it may have exited); err=%s", p.Pid, err)
+				continue
+			}
+			pi.cmdline, err = p.Cmdline()
+			if err != nil {
+				logrus.Tracef("Couldn't get process cmdline for pid=%d (it may have exited); err=%s", p.Pid, err)
+				continue
+			}
+			pi.cgroup = readProcessCgroup(p.Pid)
+		}
+		seen[p.Pid] = pi
+		samples = append(samples, processSample(p, pi))
+	}
+	s.procInfo = seen
+
+	return samples, nil
+}
+
+func processSample(p *process.Process, pi procInfo) ProcessSample {
+	ps := ProcessSample{
+		Pid:     p.Pid,
+		Name:    pi.name,
+		Cmdline: pi.cmdline,
+		Cgroup:  pi.cgroup,
+	}
+
+	//cpu usage
+	timestats, err := p.Times()
+	if err != nil {
+		logrus.Warnf("Error getting process CPUTimes for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.Times = timestats
+	}
+
+	//network connection count
+	connstats, err := p.Connections()
+	if err != nil {
+		logrus.Warnf("Error getting process Connections for pid=%d; err=%s", p.Pid, err)
+	} else {
+		c := len(connstats)
+		ps.Connections = &c
+		ps.Listens = listens(connstats)
+	}
+
+	//network io overall
+	netiostats, err := p.NetIOCounters(false)
+	if err != nil {
+		logrus.Warnf("Error getting process overall NETIOCounters for pid=%d; err=%s", p.Pid, err)
+	} else if len(netiostats) > 0 {
+		ps.TotalNetIOCounters = &netiostats[0]
+	}
+
+	//network io per interface
+	netiostats, err = p.NetIOCounters(true)
+	if err != nil {
+		logrus.Warnf("Error getting process NETIOCounters per nic for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.NetIOCounters = netiostats
+	}
+
+	//io counters
+	cs, err := p.IOCounters()
+	if err != nil {
+		logrus.Warnf("Error getting process IOCounters for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.IOCounters = cs
+	}
+
+	//ram memory
+	mp, err := p.MemoryPercent()
+	if err != nil {
+		logrus.Warnf("Error getting process MemoryPercent for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.MemoryPercent = &mp
+	}
+
+	mi, err := p.MemoryInfo()
+	if err != nil {
+		logrus.Warnf("Error getting process MemoryInfo for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.MemoryInfo = mi
+	}
+
+	//file descriptors
+	fd, err := p.NumFDs()
+	if err != nil {
+		logrus.Warnf("Error getting process NumFDs for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.NumFDs = &fd
+	}
+
+	//open files
+	of, err := p.OpenFiles()
+	if err != nil {
+		logrus.Warnf("Error getting process OpenFiles for pid=%d; err=%s", p.Pid, err)
+	} else {
+		c := len(of)
+		ps.OpenFiles = &c
+	}
+
+	//threads and resource limits
+	uids, err := p.Uids()
+	if err != nil || len(uids) == 0 {
+		logrus.Warnf("Error getting process Uids for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.UID = &uids[0]
+	}
+
+	nt, err := p.NumThreads()
+	if err != nil {
+		logrus.Warnf("Error getting process NumThreads for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.NumThreads = &nt
+	}
+
+	pl, err := readProcessLimits(HostProc(strconv.Itoa(int(p.Pid)), "limits"))
+	if err != nil {
+		logrus.Warnf("Error getting process limits for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.Limits = &pl
+	}
+
+	//context switches
+	ctxs, err := p.NumCtxSwitches()
+	if err != nil {
+		logrus.Warnf("Error getting process NumCtxSwitches for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.CtxSwitches = ctxs
+	}
+
+	//state and parent
+	ps.State, err = p.Status()
+	if err != nil {
+		logrus.Warnf("Error getting process Status for pid=%d; err=%s", p.Pid, err)
+	}
+	ppid, err := p.Ppid()
+	if err != nil {
+		logrus.Warnf("Error getting process Ppid for pid=%d; err=%s", p.Pid, err)
+	} else {
+		ps.PPid = &ppid
+	}
+	if ps.State == ProcessStateUninterruptible {
+		//where the process is blocked, for finding stuck mounts
+		ps.Wchan = readWchan(HostProc(strconv.Itoa(int(p.Pid)), "wchan"))
+		ps.Cwd, _ = p.Cwd()
+	}
+
+	return ps
+}
+
+func (s *GopsutilSource) Cgroups() ([]CgroupSample, error) {
+	return readCgroups(HostSys("fs", "cgroup"))
+}
+
+func (s *GopsutilSource) Pressure() (PressureSample, error) {
+	return readPressure(HostProc("pressure"))
+}
+
+func (s *GopsutilSource) NetSNMP() (NetSNMPSample, error) {
+	return readNetSNMP(HostProc("net", "snmp"), HostProc("net", "netstat"))
+}
+
+func (s *GopsutilSource) Load() (LoadSample, error) {
+	return readLoad(HostProc("loadavg"), HostProc("stat"))
+}
+
+func (s *GopsutilSource) Tasks() (TaskSample, error) {
+	return readTasks(HostProc("loadavg"), HostProc("sys", "kernel", "pid_max"), HostProc("sys", "kernel", "threads-max"))
+}
+
+func (s *GopsutilSource) Sockets() (SocketSample, error) {
+	return readSockets(HostProc("sys", "net", "ipv4", "ip_local_port_range"), hostNetFile("tcp"), hostNetFile("tcp6"))
+}
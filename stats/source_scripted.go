@@ -0,0 +1,244 @@
+package stats
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+)
+
+//ErrTimelineEnd returned by ScriptedSource when there are no more samples for a sequence
+var ErrTimelineEnd = errors.New("End of timeline")
+
+//Timeline sequences of samples for each kind of data provided by a Source
+type Timeline struct {
+	CPU        []CPUSample
+	Mem        []MemSample
+	FD         []FDSample
+	DiskIO     []map[string]disk.IOCountersStat
+	Partitions [][]disk.UsageStat
+	NICs       [][]net.IOCountersStat
+	Processes  [][]ProcessSample
+	Cgroups    [][]CgroupSample
+	Pressure   []PressureSample
+	NetSNMP    []NetSNMPSample
+	Sockets    []SocketSample
+	Load       []LoadSample
+	Tasks      []TaskSample
+}
+
+//ScriptedSource a Source that replays a Timeline from memory
+//Each call to one of its methods returns the next sample of the corresponding
+//sequence, so the timeline advances at the sampling pace of the collectors
+//After the last sample of a sequence, ErrTimelineEnd is returned. Sequences of
+//collections (disks, partitions, nics, processes and cgroups) that are empty in the
+//timeline return empty results instead
+type ScriptedSource struct {
+	timeline Timeline
+	cursors  map[string]int
+	m        sync.Mutex
+}
+
+//NewScriptedSource creates a new Source that replays timeline
+func NewScriptedSource(timeline Timeline) *ScriptedSource {
+	return &ScriptedSource{
+		timeline: timeline,
+		cursors:  make(map[string]int),
+	}
+}
+
+//next returns the index of the next sample of a sequence with size l
+func (s *ScriptedSource) next(seq string, l int) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	i := s.cursors[seq]
+	if i >= l {
+		return -1, ErrTimelineEnd
+	}
+	s.cursors[seq] = i + 1
+	return i, nil
+}
+
+//Replayed returns how many samples were returned from the sequence that was
+//replayed the least. Sequences that are empty in the timeline are ignored
+func (s *ScriptedSource) Replayed() int {
+	tl := s.timeline
+	lens := map[string]int{
+		"cpu":        len(tl.CPU),
+		"mem":        len(tl.Mem),
+		"fd":         len(tl.FD),
+		"diskio":     len(tl.DiskIO),
+		"partitions": len(tl.Partitions),
+		"nics":       len(tl.NICs),
+		"processes":  len(tl.Processes),
+		"cgroups":    len(tl.Cgroups),
+		"pressure":   len(tl.Pressure),
+		"netsnmp":    len(tl.NetSNMP),
+		"sockets":    len(tl.Sockets),
+		"load":       len(tl.Load),
+		"tasks":      len(tl.Tasks),
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	replayed := -1
+	for seq, l := range lens {
+		if l == 0 {
+			continue
+		}
+		if replayed == -1 || s.cursors[seq] < replayed {
+			replayed = s.cursors[seq]
+		}
+	}
+	if replayed == -1 {
+		return 0
+	}
+	return replayed
+}
+
+func (s *ScriptedSource) CPUCount() (int, error) {
+	if len(s.timeline.CPU) == 0 {
+		return 0, nil
+	}
+	return len(s.timeline.CPU[0].CPUs), nil
+}
+
+func (s *ScriptedSource) CPUTimes() (CPUSample, error) {
+	i, err := s.next("cpu", len(s.timeline.CPU))
+	if err != nil {
+		return CPUSample{}, err
+	}
+	return s.timeline.CPU[i], nil
+}
+
+func (s *ScriptedSource) Memory() (MemSample, error) {
+	i, err := s.next("mem", len(s.timeline.Mem))
+	if err != nil {
+		return MemSample{}, err
+	}
+	return s.timeline.Mem[i], nil
+}
+
+func (s *ScriptedSource) FileDescriptors() (FDSample, error) {
+	i, err := s.next("fd", len(s.timeline.FD))
+	if err != nil {
+		return FDSample{}, err
+	}
+	return s.timeline.FD[i], nil
+}
+
+func (s *ScriptedSource) DiskIOCounters() (map[string]disk.IOCountersStat, error) {
+	if len(s.timeline.DiskIO) == 0 {
+		return nil, nil
+	}
+	i, err := s.next("diskio", len(s.timeline.DiskIO))
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline.DiskIO[i], nil
+}
+
+func (s *ScriptedSource) Partitions() ([]disk.UsageStat, error) {
+	if len(s.timeline.Partitions) == 0 {
+		return nil, nil
+	}
+	i, err := s.next("partitions", len(s.timeline.Partitions))
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline.Partitions[i], nil
+}
+
+func (s *ScriptedSource) NetIOCounters() ([]net.IOCountersStat, error) {
+	if len(s.timeline.NICs) == 0 {
+		return nil, nil
+	}
+	i, err := s.next("nics", len(s.timeline.NICs))
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline.NICs[i], nil
+}
+
+func (s *ScriptedSource) Processes() ([]ProcessSample, error) {
+	if len(s.timeline.Processes) == 0 {
+		return nil, nil
+	}
+	i, err := s.next("processes", len(s.timeline.Processes))
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline.Processes[i], nil
+}
+
+func (s *ScriptedSource) Cgroups() ([]CgroupSample, error) {
+	if len(s.timeline.Cgroups) == 0 {
+		return nil, nil
+	}
+	i, err := s.next("cgroups", len(s.timeline.Cgroups))
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline.Cgroups[i], nil
+}
+
+//Pressure returns unsupported samples when the timeline has no pressure samples,
+//as on kernels without pressure stall information
+func (s *ScriptedSource) Pressure() (PressureSample, error) {
+	if len(s.timeline.Pressure) == 0 {
+		return PressureSample{}, nil
+	}
+	i, err := s.next("pressure", len(s.timeline.Pressure))
+	if err != nil {
+		return PressureSample{}, err
+	}
+	return s.timeline.Pressure[i], nil
+}
+
+//NetSNMP returns zeroed counters when the timeline has no net snmp samples
+func (s *ScriptedSource) NetSNMP() (NetSNMPSample, error) {
+	if len(s.timeline.NetSNMP) == 0 {
+		return NetSNMPSample{}, nil
+	}
+	i, err := s.next("netsnmp", len(s.timeline.NetSNMP))
+	if err != nil {
+		return NetSNMPSample{}, err
+	}
+	return s.timeline.NetSNMP[i], nil
+}
+
+//Sockets returns no sockets when the timeline has no socket samples
+func (s *ScriptedSource) Sockets() (SocketSample, error) {
+	if len(s.timeline.Sockets) == 0 {
+		return SocketSample{}, nil
+	}
+	i, err := s.next("sockets", len(s.timeline.Sockets))
+	if err != nil {
+		return SocketSample{}, err
+	}
+	return s.timeline.Sockets[i], nil
+}
+
+//Load returns no load when the timeline has no load samples
+func (s *ScriptedSource) Load() (LoadSample, error) {
+	if len(s.timeline.Load) == 0 {
+		return LoadSample{}, nil
+	}
+	i, err := s.next("load", len(s.timeline.Load))
+	if err != nil {
+		return LoadSample{}, err
+	}
+	return s.timeline.Load[i], nil
+}
+
+//Tasks returns no tasks when the timeline has no task samples
+func (s *ScriptedSource) Tasks() (TaskSample, error) {
+	if len(s.timeline.Tasks) == 0 {
+		return TaskSample{}, nil
+	}
+	i, err := s.next("tasks", len(s.timeline.Tasks))
+	if err != nil {
+		return TaskSample{}, err
+	}
+	return s.timeline.Tasks[i], nil
+}
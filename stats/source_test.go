@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptedSourceSequence(t *testing.T) {
+	s := NewScriptedSource(Timeline{
+		FD: []FDSample{{Used: 10, Max: 100}, {Used: 20, Max: 100}},
+	})
+
+	fd, err := s.FileDescriptors()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), fd.Used)
+	fd, err = s.FileDescriptors()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), fd.Used)
+	_, err = s.FileDescriptors()
+	assert.Equal(t, ErrTimelineEnd, err)
+
+	//sequences are independent
+	_, err = s.Memory()
+	assert.Equal(t, ErrTimelineEnd, err)
+	ps, err := s.Processes()
+	assert.Nil(t, err)
+	assert.Empty(t, ps)
+}
+
+func TestScriptedSourceReplayed(t *testing.T) {
+	s := NewScriptedSource(Timeline{
+		FD:  []FDSample{{Used: 10, Max: 100}, {Used: 20, Max: 100}},
+		Mem: []MemSample{{}, {}},
+	})
+	assert.Equal(t, 0, s.Replayed())
+
+	s.FileDescriptors()
+	s.FileDescriptors()
+	assert.Equal(t, 0, s.Replayed())
+
+	//empty sequences are not considered
+	s.Processes()
+	s.Memory()
+	assert.Equal(t, 1, s.Replayed())
+	s.Memory()
+	assert.Equal(t, 2, s.Replayed())
+}
+
+func TestScriptedCPUStats(t *testing.T) {
+	tl := Timeline{}
+	for i := 0; i < 50; i++ {
+		//cpu0 always busy and cpu1 always idle
+		v := float64(i) * 0.1
+		tl.CPU = append(tl.CPU, CPUSample{
+			Total: cpu.TimesStat{User: v, Idle: v},
+			CPUs: []cpu.TimesStat{
+				{CPU: "cpu0", User: v},
+				{CPU: "cpu1", Idle: v},
+			},
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewCPUStats(ctx, NewScriptedSource(tl), 60*time.Second, 10)
+	assert.Equal(t, 2, len(s.CPU))
+
+	time.Sleep(2 * time.Second)
+	tc, ok := TimeLoadPerc(&s.CPU[0].User, 1*time.Second)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, tc, 0.1)
+	tc, ok = TimeLoadPerc(&s.Total.Idle, 1*time.Second)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.5, tc, 0.1)
+}
+
+func TestScriptedDiskStats(t *testing.T) {
+	tl := Timeline{}
+	for i := 0; i < 50; i++ {
+		tl.DiskIO = append(tl.DiskIO, map[string]disk.IOCountersStat{
+			"sda": {Name: "sda", WriteBytes: uint64(i) * 1000000},
+		})
+		tl.Partitions = append(tl.Partitions, []disk.UsageStat{
+			{Path: "/", Fstype: "ext4", Total: 1000, Free: 400},
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewDiskStats(ctx, NewScriptedSource(tl), 60*time.Second, 1*time.Second, 10)
+
+	time.Sleep(2 * time.Second)
+	rate, ok := s.Disks["sda"].WriteBytes.Rate(1 * time.Second)
+	assert.True(t, ok)
+	assert.InDelta(t, 10000000, rate, 1000000)
+	assert.Equal(t, uint64(1000), s.Partitions["/"].Total)
+	assert.Equal(t, "ext4", s.Partitions["/"].Fstype)
+}
@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaviostutz/signalutils"
+	"github.com/sirupsen/logrus"
+)
+
+//TaskStats number of tasks (processes and threads) and the kernel limits on them
+type TaskStats struct {
+	//Tasks processes and threads. Each thread uses a pid
+	Tasks signalutils.Timeseries
+	//PidMax kernel.pid_max. 0 if unknown
+	PidMax uint64
+	//ThreadsMax kernel.threads-max. 0 if unknown
+	ThreadsMax uint64
+	source     Source
+}
+
+func NewTaskStats(ctx context.Context, source Source, timeseriesMaxSpan time.Duration, sampleFreq float64) *TaskStats {
+	logrus.Tracef("Task Stats: initializing...")
+	t := &TaskStats{
+		Tasks:  signalutils.NewTimeseries(timeseriesMaxSpan),
+		source: sourceOrDefault(source),
+	}
+	signalutils.StartWorker(ctx, "tasks", t.tasksStep, sampleFreq/2, sampleFreq, true)
+	logrus.Debugf("Task Stats: running")
+	return t
+}
+
+func (t *TaskStats) tasksStep() error {
+	s, err := t.source.Tasks()
+	if err != nil {
+		return err
+	}
+	t.Tasks.Add(float64(s.Tasks))
+	t.PidMax = s.PidMax
+	t.ThreadsMax = s.ThreadsMax
+	return nil
+}
+
+//readTasks reads the number of tasks from loadavgFile (/proc/loadavg) and
+//the limits from pidMaxFile (/proc/sys/kernel/pid_max) and threadsMaxFile (/proc/sys/kernel/threads-max)
+func readTasks(loadavgFile string, pidMaxFile string, threadsMaxFile string) (TaskSample, error) {
+	ts := TaskSample{}
+
+	b, err := ioutil.ReadFile(loadavgFile)
+	if err != nil {
+		return ts, err
+	}
+	//ex.: "0.52 0.58 0.59 2/1023 12345". 1023 is the number of tasks
+	fields := strings.Fields(string(b))
+	if len(fields) < 4 {
+		return ts, fmt.Errorf("Invalid load average %s", string(b))
+	}
+	p := strings.SplitN(fields[3], "/", 2)
+	if len(p) != 2 {
+		return ts, fmt.Errorf("Invalid tasks %s in %s", fields[3], loadavgFile)
+	}
+	ts.Tasks, err = strconv.ParseUint(p[1], 10, 64)
+	if err != nil {
+		return ts, fmt.Errorf("Invalid tasks %s in %s. err=%s", fields[3], loadavgFile, err)
+	}
+
+	ts.PidMax = readLimit(pidMaxFile)
+	ts.ThreadsMax = readLimit(threadsMaxFile)
+	return ts, nil
+}
@@ -0,0 +1,24 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTasks(t *testing.T) {
+	ts, err := readTasks("testdata/hostroot/proc/loadavg", "testdata/hostroot/proc/sys/kernel/pid_max", "testdata/hostroot/proc/sys/kernel/threads-max")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1023), ts.Tasks)
+	assert.Equal(t, uint64(32768), ts.PidMax)
+	assert.Equal(t, uint64(63432), ts.ThreadsMax)
+
+	//limits are optional
+	ts, err = readTasks("testdata/hostroot/proc/loadavg", "testdata/hostroot/proc/none", "testdata/hostroot/proc/none")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1023), ts.Tasks)
+	assert.Equal(t, uint64(0), ts.PidMax)
+
+	_, err = readTasks("testdata/hostroot/proc/none", "testdata/hostroot/proc/sys/kernel/pid_max", "testdata/hostroot/proc/sys/kernel/threads-max")
+	assert.NotNil(t, err)
+}
@@ -21,6 +21,19 @@ func TimeLoadPerc(ts *signalutils.Timeseries, loadTime time.Duration) (float64,
 	return vd / td, true
 }
 
+//TimeValuesRange values of ts between from and to
+//Timeseries.ValuesRange returns all values, regardless of the time range
+func TimeValuesRange(ts *signalutils.Timeseries, from time.Time, to time.Time) []signalutils.TimeValue {
+	all, _ := ts.ValuesRange(from, to)
+	tvs := make([]signalutils.TimeValue, 0)
+	for _, tv := range all {
+		if !tv.Time.Before(from) && !tv.Time.After(to) {
+			tvs = append(tvs, tv)
+		}
+	}
+	return tvs
+}
+
 func ValuesAvg(ts *signalutils.Timeseries, timeSpan time.Duration) (float64, bool) {
 	v1, ok := ts.Get(time.Now().Add(-timeSpan))
 	if !ok {